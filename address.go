@@ -11,17 +11,29 @@ const (
 	Secp384r1  = Algorithm(`secp384r1`)
 	Secp521r1  = Algorithm(`secp521r1`)
 	Prime256v1 = Algorithm(`prime256v1`) // OpenSSL
+	Jubjub     = Algorithm(`jubjub`)     // Zcash Sapling https://zips.z.cash/zip-0032
+	Pallas     = Algorithm(`pallas`)     // Zcash Orchard
+	// Cardano Icarus https://cips.cardano.org/cip/CIP-1852
+	Ed25519Bip32 = Algorithm(`ed25519-bip32`)
 
 	// Address formats
 
-	HEX    = Format(`hex`)
-	P2PKH  = Format(`p2pkh`)
-	P2S4   = Format(`p2s4`)
-	P2WPKH = Format(`p2wpkh`)
-	Bech32 = Format(`bech32`)
-	Base58 = Format(`base58`)
+	HEX        = Format(`hex`)
+	P2PKH      = Format(`p2pkh`)
+	P2S4       = Format(`p2s4`)
+	P2SH       = Format(`p2sh`)
+	P2SHP2WPKH = Format(`p2sh-p2wpkh`) // BIP49 nested SegWit
+	P2WPKH     = Format(`p2wpkh`)
+	P2TR       = Format(`p2tr`)       // BIP86 Taproot, bech32m encoded
+	P2SHP2WSH  = Format(`p2sh-p2wsh`) // BIP48 nested SegWit multisig
+	P2WSH      = Format(`p2wsh`)      // BIP48 native SegWit multisig
+	Bech32     = Format(`bech32`)
+	Base58     = Format(`base58`)
 
 	SS58 = Format(`ss58`)
+
+	Sapling = Format(`sapling`) // Zcash shielded Sapling address
+	Unified = Format(`unified`) // Zcash unified address https://zips.z.cash/zip-0316
 )
 
 type Algorithm string
@@ -30,22 +42,121 @@ type Format string
 
 var (
 	indexAlgorithms = map[Algorithm]bool{
-		Secp256k1:  true,
-		Ed25519:    true,
-		Sr25519:    true,
-		Secp256r1:  true,
-		Secp384r1:  true,
-		Secp521r1:  true,
-		Prime256v1: true,
+		Secp256k1:    true,
+		Ed25519:      true,
+		Sr25519:      true,
+		Secp256r1:    true,
+		Secp384r1:    true,
+		Secp521r1:    true,
+		Prime256v1:   true,
+		Jubjub:       true,
+		Pallas:       true,
+		Ed25519Bip32: true,
 	}
 
 	indexFormats = map[Format]bool{
-		HEX:    true,
-		P2PKH:  true,
-		P2S4:   true,
-		P2WPKH: true,
-		Bech32: true,
-		Base58: true,
-		SS58:   true,
+		HEX:        true,
+		P2PKH:      true,
+		P2S4:       true,
+		P2SH:       true,
+		P2SHP2WPKH: true,
+		P2WPKH:     true,
+		P2TR:       true,
+		P2SHP2WSH:  true,
+		P2WSH:      true,
+		Bech32:     true,
+		Base58:     true,
+		SS58:       true,
+		Sapling:    true,
+		Unified:    true,
 	}
 )
+
+// addressDefaults are address params, used when "aa", "af" or "ap" are not defined
+type addressDefaults struct {
+	algorithm Algorithm
+	format    Format
+	prefix    string
+	// testnetPrefix replaces prefix for test networks, see testnetChainIds
+	testnetPrefix string
+}
+
+// levelOverrides are address params by values of path level
+type levelOverrides struct {
+	role   levelRole
+	values map[uint32]addressDefaults
+}
+
+var (
+	networkDefaults = map[NetworkType]addressDefaults{
+		Bitcoin:     {algorithm: Secp256k1, format: P2PKH, prefix: `1`},
+		EthereumVM:  {algorithm: Secp256k1, format: HEX, prefix: `0x`},
+		AvalancheVM: {algorithm: Secp256k1, format: HEX, prefix: `X-avax`},
+		TronVM:      {algorithm: Secp256k1, format: Base58, prefix: `T`},
+		Cosmos:      {algorithm: Secp256k1, format: Bech32},
+		Solana:      {algorithm: Ed25519, format: Base58},
+		Zcash:       {algorithm: Secp256k1, format: P2PKH, prefix: `t1`, testnetPrefix: `tm`},
+		Cardano:     {algorithm: Ed25519Bip32, format: Bech32, prefix: `addr1`, testnetPrefix: `addr_test1`},
+		Polkadot:    {algorithm: Sr25519, format: SS58},
+	}
+
+	// derivationDefaults overrides network defaults for derivation types
+	derivationDefaults = map[NetworkType]map[DerivationType]addressDefaults{
+		Bitcoin: {
+			BIP49: {algorithm: Secp256k1, format: P2SHP2WPKH, prefix: `3`, testnetPrefix: `2`},
+			BIP84: {algorithm: Secp256k1, format: P2WPKH, prefix: `bc1q`, testnetPrefix: `tb1q`},
+			BIP86: {algorithm: Secp256k1, format: P2TR, prefix: `bc1p`, testnetPrefix: `tb1p`},
+		},
+		Zcash: {
+			ZIP32: {algorithm: Jubjub, format: Sapling, prefix: `zs`, testnetPrefix: `ztestsapling`},
+		},
+	}
+
+	// levelDefaults overrides derivation defaults by value of path level
+	levelDefaults = map[NetworkType]map[DerivationType]levelOverrides{
+		Bitcoin: {
+			BIP48: {role: levelScript, values: map[uint32]addressDefaults{
+				uint32(ScriptP2SHP2WSH): {algorithm: Secp256k1, format: P2SHP2WSH, prefix: `3`, testnetPrefix: `2`},
+				uint32(ScriptP2WSH):     {algorithm: Secp256k1, format: P2WSH, prefix: `bc1q`, testnetPrefix: `tb1q`},
+			}},
+		},
+		Cardano: {
+			CIP1852: {role: levelCharge, values: map[uint32]addressDefaults{
+				uint32(StakingRole): {algorithm: Ed25519Bip32, format: Bech32, prefix: `stake1`, testnetPrefix: `stake_test1`},
+			}},
+		},
+	}
+
+	// testnetChainIds are chain ids of test networks
+	testnetChainIds = map[NetworkType]map[ChainId]bool{
+		Bitcoin: {`testnet`: true, `testnet3`: true, `testnet4`: true, `signet`: true, `regtest`: true},
+		Zcash:   {`test`: true, `regtest`: true},
+		Cardano: {`preprod`: true, `preview`: true},
+	}
+)
+
+func defaultsFor(chain *Chain, path *DerivationPath) addressDefaults {
+	if chain == nil {
+		return addressDefaults{}
+	}
+
+	defaults := networkDefaults[chain.networkType]
+
+	if byType, ok := derivationDefaults[chain.networkType][path.Type()]; ok {
+		defaults = byType
+	}
+
+	if overrides, ok := levelDefaults[chain.networkType][path.Type()]; ok {
+		if level, ok := path.level(overrides.role); ok {
+			if byLevel, ok := overrides.values[level.Index]; ok {
+				defaults = byLevel
+			}
+		}
+	}
+
+	if defaults.testnetPrefix != `` && testnetChainIds[chain.networkType][chain.chainId] {
+		defaults.prefix = defaults.testnetPrefix
+	}
+
+	return defaults
+}
@@ -0,0 +1,60 @@
+package go_mhda
+
+// Canonicalize returns canonical form of URN, so different spellings of the same address
+// produce the same string. Canonical form is:
+//   - "urn:mhda:" prefix and components keys in lowercase
+//   - components in order nt, ct, ci, dt, dp, aa, af, ap, as, "sv" is omitted
+//   - "dt" and "dp" omitted for root keys
+//   - hardened levels of "dp" marked with "'", levels without leading zeros
+//   - "ct" in decimal, numeric "ci" of evm and avm networks in lowercase hex with "0x" prefix
+//   - "aa", "af" and "ap" omitted, when equal to defaults of network and derivation types
+//   - values percent-encoded with uppercase hex digits, only when required
+//
+// r-, q- and f-components are not a part of address identity and are dropped
+func Canonicalize(src string) (string, error) {
+	address, err := ParseURN(src)
+
+	if err != nil {
+		return ``, err
+	}
+
+	return prefixMHDA + address.NSS(), nil
+}
+
+// CanonicalizeNSS returns canonical form of NSS, see Canonicalize
+func CanonicalizeNSS(src string) (string, error) {
+	address, err := ParseNSS(src)
+
+	if err != nil {
+		return ``, err
+	}
+
+	return address.NSS(), nil
+}
+
+// Equal returns true, when addresses have the same canonical NSS. Short and long forms,
+// hardened markers, hex and decimal chain ids and components order are not significant
+func Equal(a, b MHDA) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.NSS() == b.NSS()
+}
+
+// EquivalentURN parses both URNs and compares them with Equal
+func EquivalentURN(x, y string) (bool, error) {
+	a, err := ParseURN(x)
+
+	if err != nil {
+		return false, err
+	}
+
+	b, err := ParseURN(y)
+
+	if err != nil {
+		return false, err
+	}
+
+	return Equal(a, b), nil
+}
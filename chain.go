@@ -1,7 +1,6 @@
 package go_mhda
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -29,8 +28,9 @@ var (
 	rxChainComponents = regexp.MustCompile(`:(nt|ct|ci):([0-9a-z-._~*+=%$&@?'()!,;/#]+)`)
 )
 
+// NewChain creates chain, network type is lowercased
 func NewChain(networkType NetworkType, coinType CoinType, chainId ChainId) *Chain {
-	return &Chain{networkType: networkType, coinType: coinType, chainId: chainId}
+	return &Chain{networkType: NetworkType(strings.ToLower(string(networkType))), coinType: coinType, chainId: chainId}
 }
 
 func ChainFromKey(chainKey ChainKey) (*Chain, error) {
@@ -38,55 +38,61 @@ func ChainFromKey(chainKey ChainKey) (*Chain, error) {
 }
 
 func ChainFromNSS(src string) (*Chain, error) {
-	var componentsNamesTmp = make([]string, len(componentsNames))
+	var components nssComponents
 
-	copy(componentsNamesTmp, componentsNames)
-
-	components, err := parseNSS(src, componentsNamesTmp)
+	err := parseNSS(src, &components, &LenientParseOptions)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if _, ok := components[compNetworkType]; !ok {
-		return nil, errors.New(`"nt" not defined`)
+	if !components.values[indexNetworkType].isSet {
+		return nil, newParseError(compNetworkType, 0, ``, ErrMissingNetworkType)
 	}
 
-	return parseChain(components)
+	chain := &Chain{}
+
+	err = chain.setComponents(&components)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return chain, nil
 }
 
-func parseChain(m map[string]string) (*Chain, error) {
-	networkType := strings.TrimSpace(m[compNetworkType])
+func (c *Chain) setComponents(m *nssComponents) error {
+	networkType := strings.ToLower(strings.TrimSpace(m.values[indexNetworkType].value))
 
-	// TODO: Check coin type extraction from derivation path??? subnets???
 	if networkType == `` {
-		return nil, errors.New(`"networkType" required`)
+		return newParseError(compNetworkType, m.values[indexNetworkType].offset, ``, ErrMissingNetworkType)
 	}
 
-	ct := strings.TrimSpace(m[compCoinType])
-	// TODO: Check coin type extraction from derivation path??? subnets???
+	// "ct" can be extracted from derivation path with ParseOptions.InferChain
+	ct := strings.TrimSpace(m.values[indexCoinType].value)
 	if ct == `` {
-		return nil, errors.New(`"ct" required`)
+		return newParseError(compCoinType, m.values[indexCoinType].offset, ``, ErrMissingCoinType)
 	}
 
 	coinType, err := strconv.ParseUint(ct, 0, 32)
 	if err != nil {
-		return nil, errors.New(`cannot parse "ct"`)
+		return newParseError(compCoinType, m.values[indexCoinType].offset, ct, ErrBadCoinType)
 	}
 
 	// TODO: Add ci validation
-	if _, ok := m[compChainId]; !ok {
-		return nil, errors.New(`numeric "ci" required for "ct=evm"`)
+	if !m.values[indexChainId].isSet {
+		return newParseError(compChainId, 0, ``, ErrMissingChainId)
 	}
-	return &Chain{
-		networkType: NetworkType(networkType), // TODO: Add validation
-		coinType:    CoinType(coinType),
-		chainId:     ChainId(m[compChainId]),
-	}, nil
+
+	c.networkType = NetworkType(networkType) // TODO: Add validation
+	c.coinType = CoinType(coinType)
+	c.chainId = ChainId(m.values[indexChainId].value)
+
+	return nil
 }
 
 func (c *Chain) SetNetworkType(networkType NetworkType) {
-	c.networkType = networkType
+	c.networkType = NetworkType(strings.ToLower(string(networkType)))
 }
 
 func (c *Chain) SetCoinType(coinType CoinType) {
@@ -108,10 +114,31 @@ func (c *Chain) CoinType() CoinType {
 func (c *Chain) ChainId() ChainId {
 	return c.chainId
 }
+
+// canonicalChainId returns chain id in canonical form. Numeric chain ids of
+// evm and avm networks are written as lowercase hex with "0x" prefix
+func (c *Chain) canonicalChainId() string {
+	if c.networkType != EthereumVM && c.networkType != AvalancheVM {
+		return string(c.chainId)
+	}
+
+	chainId, err := strconv.ParseUint(string(c.chainId), 0, 64)
+	if err != nil {
+		return string(c.chainId)
+	}
+
+	return `0x` + strconv.FormatUint(chainId, 16)
+}
+
 func (c *Chain) Key() ChainKey {
 	return ChainKey(c.String())
 }
 
 func (c *Chain) String() string {
-	return fmt.Sprintf("nt:%s:ct:%d:ci:%s", c.networkType, c.CoinType(), c.chainId)
+	return fmt.Sprintf(
+		"nt:%s:ct:%d:ci:%s",
+		escapeComponent(string(c.networkType)),
+		c.CoinType(),
+		escapeComponent(c.canonicalChainId()),
+	)
 }
@@ -27,3 +27,64 @@ func TestChainFromNSS(t *testing.T) {
 		}
 	}
 }
+
+func TestChainIdEncoding(t *testing.T) {
+	chainIds := []ChainId{
+		`cosmoshub-4`,
+		`axelar:dojo-1`,
+		`memo with spaces`,
+		`100%`,
+		`сеть`,
+		`a?b#c`,
+	}
+
+	for i := range chainIds {
+		chain := NewChain(Cosmos, ATOM, chainIds[i])
+
+		parsed, err := ChainFromKey(chain.Key())
+		if err != nil {
+			t.Fatalf("Cannot parse %s: %s", chain.Key(), err)
+		}
+
+		if parsed.ChainId() != chainIds[i] {
+			t.Fatalf("Unmatched chain id %q vs %q", parsed.ChainId(), chainIds[i])
+		}
+
+		if parsed.Key() != chain.Key() {
+			t.Fatalf("Unmatched chain key %q vs %q", parsed.Key(), chain.Key())
+		}
+	}
+
+	if chain := NewChain(`EVM`, ETH, `0x1`); chain.NetworkType() != EthereumVM {
+		t.Fatalf("unexpected network type %s", chain.NetworkType())
+	}
+
+	if chain, err := ChainFromNSS(`nt:Cosmos:ct:118:ci:cosmoshub-4`); err != nil || chain.NetworkType() != Cosmos {
+		t.Fatalf("unexpected chain %v %v", chain, err)
+	}
+
+	chain, err := ChainFromNSS(`nt:cosmos:ct:118:ci:axelar%3adojo%2D1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chain.ChainId() != `axelar:dojo-1` {
+		t.Fatalf("Unexpected decoded chain id %q", chain.ChainId())
+	}
+
+	if chain.String() != `nt:cosmos:ct:118:ci:axelar%3Adojo-1` {
+		t.Fatalf("Unexpected canonical encoding %q", chain.String())
+	}
+
+	for _, invalid := range []string{
+		`nt:cosmos:ct:118:ci:axelar%3`,
+		`nt:cosmos:ct:118:ci:axelar%zz`,
+		`nt:cosmos:ct:118:ci:axelar dojo`,
+		`nt:cosmos:ct:118:ci:`,
+		`nt:cosmos:ct:118:ci:axelar:`,
+	} {
+		if _, err = ChainFromNSS(invalid); err == nil {
+			t.Fatalf("Expected error for %s", invalid)
+		}
+	}
+}
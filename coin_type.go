@@ -16,10 +16,14 @@ const (
 	DASH = CoinType(5)
 
 	XMR  = CoinType(128)
+	DOT  = CoinType(354)
+	KSM  = CoinType(434)
 	ZEC  = CoinType(133)
+	XLM  = CoinType(148)
 	ATOM = CoinType(168)
 	TRX  = CoinType(195)
 	SOL  = CoinType(501)
+	ADA  = CoinType(1815)
 
 	//https://support.avax.network/en/articles/7004986-what-derivation-paths-does-avalanche-use
 	AVAX = CoinType(9000)
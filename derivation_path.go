@@ -1,22 +1,32 @@
 package go_mhda
 
-import (
-	"errors"
-	"fmt"
-	"regexp"
-	"strconv"
-)
+import "strconv"
 
 const (
-	ROOT  = DerivationType(`root`)
-	BIP32 = DerivationType(`bip32`)
-	BIP44 = DerivationType(`bip44`)
-	BIP84 = DerivationType(`bip84`)
-	CIP11 = DerivationType(`cip11`)
-	ZIP32 = DerivationType(`zip32`)
+	ROOT    = DerivationType(`root`)
+	BIP32   = DerivationType(`bip32`)
+	BIP44   = DerivationType(`bip44`)
+	BIP48   = DerivationType(`bip48`)
+	BIP49   = DerivationType(`bip49`)
+	BIP84   = DerivationType(`bip84`)
+	BIP86   = DerivationType(`bip86`)
+	CIP11   = DerivationType(`cip11`)
+	CIP1852 = DerivationType(`cip1852`)
+	ZIP32   = DerivationType(`zip32`)
+	// CUSTOM accepts any valid BIP32 path
+	CUSTOM = DerivationType(`custom`)
+	// SLIP10 is variable depth path with hardened levels only, used by ed25519 chains
+	SLIP10 = DerivationType(`slip10`)
+	// SUBSTRATE is path of hard and soft junctions "//hard/soft///password", used by sr25519 chains
+	SUBSTRATE = DerivationType(`substrate`)
 
 	ChargeExternal = ChargeType(0)
 	ChargeInternal = ChargeType(1)
+	// StakingRole is CIP1852 role of staking keys
+	StakingRole = ChargeType(2)
+
+	ScriptP2SHP2WSH = ScriptType(1)
+	ScriptP2WSH     = ScriptType(2)
 )
 
 type DerivationType string
@@ -25,38 +35,113 @@ type AccountIndex uint32
 
 type ChargeType uint8
 
+// ScriptType is BIP48 script type level
+type ScriptType uint32
+
 type AddressIndex struct {
 	Index      uint32
 	IsHardened bool
 }
 
+// PathLevel is single level of derivation path
+type PathLevel struct {
+	Index      uint32
+	IsHardened bool
+}
+
+// DerivationPath is list of levels, which are validated by grammar of derivation type
 type DerivationPath struct {
 	derivationType DerivationType
-	coin           CoinType
-	account        AccountIndex
-	charge         ChargeType
-	index          AddressIndex
+	levels         []PathLevel
+	// junctions and password are defined for SUBSTRATE paths only
+	junctions []Junction
+	password  string
 }
 
+// NewDerivationPath creates path with levels of derivation type grammar, fixed levels
+// are filled by grammar values, another levels by default or minimal values.
+// SLIP10 path is "m/44'/coin'/account'/charge'/index'", CUSTOM path has levels of BIP44
 func NewDerivationPath(derivationType DerivationType, coin CoinType, account AccountIndex, charge ChargeType, index AddressIndex) *DerivationPath {
+	grammar := derivationIndex[derivationType]
+
+	if derivationType == CUSTOM {
+		grammar = grammarBip44
+	}
+
+	levels := make([]PathLevel, 0, len(grammar)+1)
+
+	for i := range grammar {
+		if grammar[i].repeated {
+			// repeated levels of SLIP10 are charge and address index
+			isHardened := grammar[i].hardened == hardenedRequired
+
+			levels = append(levels,
+				PathLevel{Index: uint32(charge), IsHardened: isHardened},
+				PathLevel{Index: index.Index, IsHardened: isHardened || index.IsHardened},
+			)
+			break
+		}
+
+		level := PathLevel{
+			Index:      grammar[i].min,
+			IsHardened: grammar[i].hardened == hardenedRequired,
+		}
+
+		if grammar[i].value != 0 {
+			level.Index = grammar[i].value
+		}
+
+		switch grammar[i].role {
+		case levelCoin:
+			level.Index = uint32(coin)
+		case levelAccount:
+			level.Index = uint32(account)
+		case levelCharge:
+			level.Index = uint32(charge)
+		case levelIndex:
+			level.Index = index.Index
+			level.IsHardened = level.IsHardened ||
+				(grammar[i].hardened == hardenedOptional && index.IsHardened)
+		}
+
+		if grammar[i].fixed {
+			level.Index = grammar[i].value
+		}
+
+		levels = append(levels, level)
+	}
+
 	return &DerivationPath{
 		derivationType: derivationType,
-		coin:           coin,
-		account:        account,
-		charge:         charge,
-		index:          index,
+		levels:         levels,
 	}
 }
 
-func ParseDerivationPath(dt DerivationType, path string) (*DerivationPath, error) {
-	rx, ok := derivationIndex[dt]
+// NewDerivationPathFromLevels creates path from levels, validated by grammar of derivation type
+func NewDerivationPathFromLevels(derivationType DerivationType, levels ...PathLevel) (*DerivationPath, error) {
+	grammar, ok := derivationIndex[derivationType]
 
 	if !ok {
-		return nil, errors.New("wrong derivation type")
+		return nil, newParseError(compDerivationType, 0, string(derivationType), ErrBadDerivationType)
+	}
+
+	if !grammar.isValid(levels) {
+		return nil, newParseError(compDerivationPath, 0, formatLevels(levels), ErrBadDerivationPath)
+	}
+
+	dp := &DerivationPath{
+		derivationType: derivationType,
+		levels:         make([]PathLevel, len(levels)),
 	}
 
-	if !rx.MatchString(path) {
-		return nil, errors.New("incorrect derivation path")
+	copy(dp.levels, levels)
+
+	return dp, nil
+}
+
+func ParseDerivationPath(dt DerivationType, path string) (*DerivationPath, error) {
+	if _, ok := derivationIndex[dt]; !ok {
+		return nil, newParseError(compDerivationType, 0, string(dt), ErrBadDerivationType)
 	}
 
 	dPath := &DerivationPath{
@@ -76,137 +161,500 @@ func (dp *DerivationPath) DerivationType() DerivationType {
 	return dp.derivationType
 }
 
+// Type returns derivation type, ROOT for nil or undefined path
+func (dp *DerivationPath) Type() DerivationType {
+	if dp == nil || dp.derivationType == `` {
+		return ROOT
+	}
+	return dp.derivationType
+}
+
+// Levels returns copy of path levels
+func (dp *DerivationPath) Levels() []PathLevel {
+	if dp == nil {
+		return nil
+	}
+
+	result := make([]PathLevel, len(dp.levels))
+	copy(result, dp.levels)
+
+	return result
+}
+
+// Depth returns number of path levels or junctions
+func (dp *DerivationPath) Depth() int {
+	if dp == nil {
+		return 0
+	}
+	return len(dp.levels) + len(dp.junctions)
+}
+
+// level returns path level of grammar role
+func (dp *DerivationPath) level(role levelRole) (PathLevel, bool) {
+	grammar := derivationIndex[dp.Type()]
+
+	for i := range grammar {
+		if grammar[i].role == role {
+			if i < len(dp.levels) {
+				return dp.levels[i], true
+			}
+			break
+		}
+	}
+
+	return PathLevel{}, false
+}
+
 func (dp *DerivationPath) Coin() CoinType {
-	return dp.coin
+	level, _ := dp.level(levelCoin)
+	return CoinType(level.Index)
 }
 
 func (dp *DerivationPath) Account() AccountIndex {
-	return dp.account
+	level, _ := dp.level(levelAccount)
+	return AccountIndex(level.Index)
 }
 
 func (dp *DerivationPath) Charge() ChargeType {
-	return dp.charge
+	level, _ := dp.level(levelCharge)
+	return ChargeType(level.Index)
+}
+
+// ScriptType returns script type of BIP48 path, zero for another types
+func (dp *DerivationPath) ScriptType() ScriptType {
+	level, _ := dp.level(levelScript)
+	return ScriptType(level.Index)
 }
 
 func (dp *DerivationPath) AddressIndex() AddressIndex {
-	return dp.index
+	level, _ := dp.level(levelIndex)
+	return AddressIndex{
+		Index:      level.Index,
+		IsHardened: level.IsHardened,
+	}
 }
 
 func (dp *DerivationPath) IsHardenedAddress() bool {
-	return dp.index.IsHardened
+	return dp.AddressIndex().IsHardened
 }
 
-var (
-	rxRoot = regexp.MustCompile("")
+const (
+	// maxLevelIndex is maximal index of path level, hardened levels are encoded with 2^31 offset
+	maxLevelIndex = 1<<31 - 1
+	// maxPathDepth is maximal number of path levels, depth is encoded by single byte
+	maxPathDepth = 255
+)
 
+type levelRole uint8
+
+const (
+	levelPurpose levelRole = iota
+	levelCoin
+	levelAccount
+	levelScript
+	levelCharge
+	levelIndex
+	levelSegment
+)
+
+type hardenedRule uint8
+
+const (
+	hardenedRequired hardenedRule = iota
+	hardenedForbidden
+	hardenedOptional
+)
+
+// levelRule describes single level of derivation path grammar
+type levelRule struct {
+	role     levelRole
+	hardened hardenedRule
+	// fixed level must be equal to value, value of not fixed level is default of NewDerivationPath
+	fixed bool
+	value uint32
+	// limit is maximal level value, zero for any
+	limit uint32
+	// min is minimal level value
+	min uint32
+	// optional level can be omitted with all following levels
+	optional bool
+	// repeated level is the last rule, which matches any number of remaining levels
+	repeated bool
+}
+
+type pathGrammar []levelRule
+
+var (
 	// https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
 	// m / account ' / charge / address
-	rxBip32 = regexp.MustCompile(`^m/([0-9]+)[Hh']/(0|1)/([0-9]+)([Hh'])?$`)
+	grammarBip32 = pathGrammar{
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelCharge, hardened: hardenedForbidden, limit: 1},
+		{role: levelIndex, hardened: hardenedOptional},
+	}
 
 	// https://github.com/bitcoin/bips/blob/master/bip-0044.mediawiki
 	// m / 44 ' / coin ' / account ' / charge / address
-	rxBip44 = regexp.MustCompile(`^m/44[Hh']/([0-9]+)[Hh']/([0-9]+)[Hh']/(0|1)/([0-9]+)([Hh'])?$`)
+	grammarBip44 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, fixed: true, value: 44},
+		{role: levelCoin, hardened: hardenedRequired},
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelCharge, hardened: hardenedForbidden, limit: 1},
+		{role: levelIndex, hardened: hardenedOptional},
+	}
+
+	// https://github.com/bitcoin/bips/blob/master/bip-0048.mediawiki
+	// m / 48 ' / coin ' / account ' / script_type ' / charge / address
+	grammarBip48 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, fixed: true, value: 48},
+		{role: levelCoin, hardened: hardenedRequired},
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelScript, hardened: hardenedRequired, min: 1, limit: 2},
+		{role: levelCharge, hardened: hardenedForbidden, limit: 1},
+		{role: levelIndex, hardened: hardenedOptional},
+	}
+
+	// https://github.com/bitcoin/bips/blob/master/bip-0049.mediawiki
+	// m / 49 ' / coin ' / account ' / charge / address
+	grammarBip49 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, fixed: true, value: 49},
+		{role: levelCoin, hardened: hardenedRequired},
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelCharge, hardened: hardenedForbidden, limit: 1},
+		{role: levelIndex, hardened: hardenedOptional},
+	}
 
 	// https://github.com/bitcoin/bips/blob/master/bip-0084.mediawiki
 	// m / 84 ' / 0 ' / account ' / charge / address
-	// TODO: Check for hardened index
-	rxBip84 = regexp.MustCompile(`^m/84[Hh']/0[Hh']/([0-9]+)[Hh']/(0|1)/([0-9]+)?$`)
+	grammarBip84 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, fixed: true, value: 84},
+		{role: levelCoin, hardened: hardenedRequired, fixed: true, value: 0},
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelCharge, hardened: hardenedForbidden, limit: 1},
+		{role: levelIndex, hardened: hardenedOptional},
+	}
+
+	// https://github.com/bitcoin/bips/blob/master/bip-0086.mediawiki
+	// m / 86 ' / coin ' / account ' / charge / address
+	grammarBip86 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, fixed: true, value: 86},
+		{role: levelCoin, hardened: hardenedRequired},
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelCharge, hardened: hardenedForbidden, limit: 1},
+		{role: levelIndex, hardened: hardenedOptional},
+	}
 
 	// https://github.com/confio/cosmos-hd-key-derivation-spec
 	// m / 44 ' / 118 ' / account ' / charge_extra / address
-	rxCip11 = regexp.MustCompile(`^m/44[Hh']/118[Hh']/([0-9]+)[Hh']/([0-9]+)/([0-9]+)([Hh'])?$`)
+	grammarCip11 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, fixed: true, value: 44},
+		{role: levelCoin, hardened: hardenedRequired, fixed: true, value: 118},
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelCharge, hardened: hardenedForbidden},
+		{role: levelIndex, hardened: hardenedOptional},
+	}
+
+	// https://cips.cardano.org/cip/CIP-1852
+	// m / 1852 ' / 1815 ' / account ' / role / address
+	grammarCip1852 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, fixed: true, value: 1852},
+		{role: levelCoin, hardened: hardenedRequired, fixed: true, value: 1815},
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelCharge, hardened: hardenedForbidden, limit: 2},
+		{role: levelIndex, hardened: hardenedForbidden},
+	}
 
 	// https://zips.z.cash/zip-0032
-	// m / 32 ' / 133 ' / account '
-	// m / 32 ' / 133 ' / account ' / address
-	// m / 32 ' / 133 ' / account ' / address '
-	rxZip32 = regexp.MustCompile("")
-
-	derivationIndex = map[DerivationType]*regexp.Regexp{
-		ROOT:  rxRoot,
-		BIP32: rxBip32,
-		BIP44: rxBip44,
-		BIP84: rxBip84,
-		CIP11: rxCip11,
-		ZIP32: rxZip32,
+	// m / 32 ' / coin ' / account '
+	// m / 32 ' / coin ' / account ' / address '
+	grammarZip32 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, fixed: true, value: 32},
+		{role: levelCoin, hardened: hardenedRequired},
+		{role: levelAccount, hardened: hardenedRequired},
+		{role: levelIndex, hardened: hardenedRequired, optional: true},
+	}
+
+	// https://github.com/satoshilabs/slips/blob/master/slip-0010.md
+	// m / purpose ' [/ coin ' [/ account ' [/ level ']*]]
+	grammarSlip10 = pathGrammar{
+		{role: levelPurpose, hardened: hardenedRequired, value: 44},
+		{role: levelCoin, hardened: hardenedRequired, optional: true},
+		{role: levelAccount, hardened: hardenedRequired, optional: true},
+		{role: levelSegment, hardened: hardenedRequired, repeated: true},
+	}
+
+	// any valid BIP32 path, levels are kept as is
+	// m / level [']*
+	grammarCustom = pathGrammar{
+		{role: levelSegment, hardened: hardenedOptional, repeated: true},
+	}
+
+	derivationIndex = map[DerivationType]pathGrammar{
+		ROOT:   {},
+		CUSTOM: grammarCustom,
+		SLIP10: grammarSlip10,
+		// junctions are parsed by parseJunctions
+		SUBSTRATE: nil,
+		BIP32:     grammarBip32,
+		BIP44:     grammarBip44,
+		BIP48:     grammarBip48,
+		BIP49:     grammarBip49,
+		BIP84:     grammarBip84,
+		BIP86:     grammarBip86,
+		CIP11:     grammarCip11,
+		CIP1852:   grammarCip1852,
+		ZIP32:     grammarZip32,
 	}
 )
 
+func isHardenedMarker(c byte) bool {
+	return c == 39 || c == 72 || c == 104 // 39 ['], 72 [H], 104 [h]
+}
+
+// parseLevel reads single path level from pos, returns its index, hardened flag
+// and position of next level
+func parseLevel(path string, pos int) (uint32, bool, int, bool) {
+	var (
+		value      uint64
+		isHardened bool
+		start      = pos
+	)
+
+	for pos < len(path) && path[pos] >= 48 && path[pos] <= 57 { // 48-57  [0-9]
+		value = value*10 + uint64(path[pos]-48)
+		if value > maxLevelIndex {
+			return 0, false, pos, false
+		}
+		pos++
+	}
+
+	if pos == start {
+		return 0, false, pos, false
+	}
+
+	if pos < len(path) && isHardenedMarker(path[pos]) {
+		isHardened = true
+		pos++
+	}
+
+	if pos < len(path) {
+		if path[pos] != 47 { // 47 [/]
+			return 0, false, pos, false
+		}
+		pos++
+		if pos == len(path) {
+			return 0, false, pos, false
+		}
+	}
+
+	return uint32(value), isHardened, pos, true
+}
+
+// ParsePath parses path according grammar of derivation type, without regexp.
+// Path is validated before levels are stored, so path is not changed on error
 func (dp *DerivationPath) ParsePath(path string) error {
-	var isAddressHardened = false
-	matches := derivationIndex[dp.derivationType].FindStringSubmatch(path)
-	// TODO: Fix serialization for different length
-	if len(matches) < 5 {
-		return errors.New(fmt.Sprintf("cannot parse path: %s", path))
+	grammar, ok := derivationIndex[dp.derivationType]
+
+	if !ok {
+		return newParseError(compDerivationType, 0, string(dp.derivationType), ErrBadDerivationType)
 	}
-	coinType, err := strconv.ParseUint(matches[1], 10, 32)
-	if err != nil {
-		return errors.New("cannot parse coin")
+
+	if dp.derivationType == ROOT {
+		if path != `` {
+			return newParseError(compDerivationPath, 0, path, ErrBadDerivationPath)
+		}
+		dp.levels = dp.levels[:0]
+		return nil
 	}
-	accountIndex, err := strconv.ParseUint(matches[2], 10, 32)
-	if err != nil {
-		return errors.New("cannot parse account")
+
+	if dp.derivationType == SUBSTRATE {
+		return dp.parseJunctions(path)
 	}
-	chargeType, err := strconv.ParseUint(matches[3], 10, 32)
-	if err != nil {
-		return errors.New("cannot parse charge")
+
+	if len(grammar) == 0 || len(path) == 0 || path[0] != 109 { // 109 [m]
+		return newParseError(compDerivationPath, 0, path, ErrBadDerivationPath)
 	}
-	addressIndex, err := strconv.ParseUint(matches[4], 10, 32)
+
+	_, depth, err := grammar.scan(path, nil)
+
 	if err != nil {
-		return errors.New("cannot parse index")
-	}
-	if len(matches) == 6 && matches[5] != "" {
-		isAddressHardened = true
+		return err
 	}
 
-	dp.coin = CoinType(coinType)
-	dp.account = AccountIndex(accountIndex)
-	dp.charge = ChargeType(chargeType)
-	dp.index = AddressIndex{
-		Index:      uint32(addressIndex),
-		IsHardened: isAddressHardened,
+	levels := dp.levels[:0]
+
+	if cap(levels) < depth {
+		levels = make([]PathLevel, 0, depth)
 	}
 
+	levels, _, _ = grammar.scan(path, levels)
+
+	dp.levels = levels
+	dp.junctions = nil
+	dp.password = ``
+
 	return nil
 }
 
-func (dp *DerivationPath) String() string {
-	var result string
+// scan validates path levels by grammar and returns path depth, levels are appended
+// to dst, when it is not nil
+func (g pathGrammar) scan(path string, dst []PathLevel) ([]PathLevel, int, error) {
+	var (
+		depth = 0
+		pos   = 1
+	)
+
+	if pos < len(path) {
+		if path[pos] != 47 || pos+1 == len(path) { // 47 [/]
+			return nil, 0, newParseError(compDerivationPath, pos, path, ErrBadDerivationPath)
+		}
+		pos++
+	}
+
+	for pos < len(path) {
+		rule, ok := g.rule(depth)
+
+		if !ok || depth == maxPathDepth {
+			return nil, 0, newParseError(compDerivationPath, pos, path, ErrBadDerivationPath)
+		}
+
+		levelPos := pos
+		value, isHardened, next, ok := parseLevel(path, pos)
+
+		if !ok || !rule.isValid(value, isHardened) {
+			return nil, 0, newParseError(compDerivationPath, levelPos, path, ErrBadDerivationPath)
+		}
+
+		if dst != nil {
+			dst = append(dst, PathLevel{Index: value, IsHardened: isHardened})
+		}
 
-	switch dp.derivationType {
+		pos = next
+		depth++
+	}
+
+	if depth < g.minDepth() {
+		return nil, 0, newParseError(compDerivationPath, pos, path, ErrBadDerivationPath)
+	}
+
+	return dst, depth, nil
+}
+
+// rule returns grammar rule of level at depth
+func (g pathGrammar) rule(depth int) (*levelRule, bool) {
+	if depth < len(g) {
+		return &g[depth], true
+	}
+
+	if len(g) > 0 && g[len(g)-1].repeated {
+		return &g[len(g)-1], true
+	}
+
+	return nil, false
+}
+
+// minDepth returns number of required levels
+func (g pathGrammar) minDepth() int {
+	for i := range g {
+		if g[i].optional || g[i].repeated {
+			return i
+		}
+	}
+	return len(g)
+}
+
+func (g pathGrammar) isValid(levels []PathLevel) bool {
+	return len(levels) >= g.minDepth() && g.isPrefix(levels)
+}
+
+// isPrefix checks, that levels are valid by grammar rules, required levels can be omitted
+func (g pathGrammar) isPrefix(levels []PathLevel) bool {
+	if len(levels) > maxPathDepth {
+		return false
+	}
+
+	for i := range levels {
+		rule, ok := g.rule(i)
+		if !ok || levels[i].Index > maxLevelIndex || !rule.isValid(levels[i].Index, levels[i].IsHardened) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsHardened returns true, when all path levels or junctions are hardened
+func (dp *DerivationPath) IsHardened() bool {
+	if dp == nil {
+		return true
+	}
+
+	for i := range dp.levels {
+		if !dp.levels[i].IsHardened {
+			return false
+		}
+	}
+
+	for i := range dp.junctions {
+		if !dp.junctions[i].IsHard {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (g pathGrammar) hasLevel(role levelRole) bool {
+	for i := range g {
+		if g[i].role == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *levelRule) isValid(value uint32, isHardened bool) bool {
+	if r.hardened == hardenedRequired && !isHardened {
+		return false
+	}
+
+	if r.hardened == hardenedForbidden && isHardened {
+		return false
+	}
+
+	if r.fixed && value != r.value {
+		return false
+	}
+
+	return value >= r.min && (r.limit == 0 || value <= r.limit)
+}
+
+// formatLevels writes levels with "'" hardened markers
+func formatLevels(levels []PathLevel) string {
+	buf := make([]byte, 0, 64)
+
+	buf = append(buf, 'm')
+
+	for i := range levels {
+		buf = append(buf, '/')
+		buf = strconv.AppendUint(buf, uint64(levels[i].Index), 10)
+
+		if levels[i].IsHardened {
+			buf = append(buf, '\'')
+		}
+	}
+
+	return string(buf)
+}
+
+// String returns path, password of SUBSTRATE path is omitted
+func (dp *DerivationPath) String() string {
+	switch dp.Type() {
 	case ROOT:
 		return ``
-	case BIP32:
-		var format = "m/%d'/%d/%d"
-		if dp.index.IsHardened {
-			format += `'`
-		}
-		return fmt.Sprintf(format, dp.account, dp.charge, dp.index.Index)
-	case BIP44:
-		var format = "m/44'/%d'/%d'/%d/%d"
-		if dp.index.IsHardened {
-			format += `'`
-		}
-		return fmt.Sprintf(format, dp.coin, dp.account, dp.charge, dp.index.Index)
-	case BIP84:
-		var format = "m/84'/%d'/%d'/%d/%d"
-		if dp.index.IsHardened {
-			format += `'`
-		}
-		return fmt.Sprintf(format, dp.coin, dp.account, dp.charge, dp.index.Index)
-	case CIP11:
-		var format = "m/44'/133'/%d'/%d/%d"
-		if dp.index.IsHardened {
-			format += `'`
-		}
-		return fmt.Sprintf(format, dp.account, dp.charge, dp.index.Index)
-	case ZIP32:
-		var format = "m/32'/133'/%d'/%d"
-		if dp.index.IsHardened {
-			format += `'`
-		}
-		return fmt.Sprintf(format, dp.account, dp.index.Index)
+	case SUBSTRATE:
+		return dp.junctionsString()
 	}
 
-	return result
+	return formatLevels(dp.levels)
 }
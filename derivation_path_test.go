@@ -0,0 +1,405 @@
+package go_mhda
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCustomDerivationPath(t *testing.T) {
+	for src, expected := range map[string]string{
+		`m`:                     `m`,
+		`m/0h/0h/5h`:            `m/0'/0'/5'`,
+		`m/44'/501'/0'`:         `m/44'/501'/0'`,
+		`m/44H/60h/0'/0/0/7/9h`: `m/44'/60'/0'/0/0/7/9'`,
+		`m/2147483647`:          `m/2147483647`,
+	} {
+		dp, err := ParseDerivationPath(CUSTOM, src)
+		if err != nil {
+			t.Fatalf("%s: %s", src, err)
+		}
+
+		if dp.String() != expected {
+			t.Errorf("%s: unexpected path %s", src, dp)
+		}
+	}
+
+	for _, src := range []string{``, `m/`, `m/0/`, `m//0`, `n/0`, `m/2147483648`, `m/0x`} {
+		if _, err := ParseDerivationPath(CUSTOM, src); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+
+	address, err := ParseURN(`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:custom:dp:m/0h/0h/5h`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address.String() != `urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:custom:dp:m/0'/0'/5'` {
+		t.Errorf("unexpected address %s", address)
+	}
+
+	levels := address.DerivationPath().Levels()
+	expected := []PathLevel{{0, true}, {0, true}, {5, true}}
+
+	if !reflect.DeepEqual(levels, expected) || address.DerivationPath().Depth() != 3 {
+		t.Errorf("unexpected levels %v", levels)
+	}
+}
+
+func TestDerivationPathLevels(t *testing.T) {
+	dp := NewDerivationPath(BIP44, 60, 2, ChargeInternal, AddressIndex{Index: 7})
+
+	if dp.String() != `m/44'/60'/2'/1/7` {
+		t.Fatalf("unexpected path %s", dp)
+	}
+
+	if dp.Coin() != 60 || dp.Account() != 2 || dp.Charge() != ChargeInternal || dp.AddressIndex().Index != 7 {
+		t.Errorf("unexpected levels %v", dp.Levels())
+	}
+
+	dp, err := NewDerivationPathFromLevels(BIP84, PathLevel{84, true}, PathLevel{0, true}, PathLevel{1, true}, PathLevel{0, false}, PathLevel{3, true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dp.String() != `m/84'/0'/1'/0/3'` || !dp.IsHardenedAddress() {
+		t.Errorf("unexpected path %s", dp)
+	}
+
+	for _, levels := range [][]PathLevel{
+		{{84, true}, {0, true}, {1, true}, {0, false}},
+		{{84, true}, {1, true}, {1, true}, {0, false}, {0, false}},
+		{{84, true}, {0, true}, {1, true}, {2, false}, {0, false}},
+		{{84, true}, {0, true}, {1, true}, {0, false}, {0, false}, {0, false}},
+		{{84, true}, {0, true}, {1 << 31, true}, {0, false}, {0, false}},
+	} {
+		if _, err = NewDerivationPathFromLevels(BIP84, levels...); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%v: unexpected error %v", levels, err)
+		}
+	}
+
+	// failed parsing keeps path
+	if err = dp.ParsePath(`m/84'/0'/1'/5/3`); err == nil || dp.String() != `m/84'/0'/1'/0/3'` {
+		t.Errorf("unexpected path %s after error %v", dp, err)
+	}
+
+	for dt, src := range map[DerivationType]string{
+		BIP49: `m/44'/0'/0'/0/0`,
+		BIP84: `m/84'/1'/0'/0/0`,
+		BIP86: `m/84'/0'/0'/0/0`,
+		BIP48: `m/48'/0'/0'/3'/0/0`,
+	} {
+		if _, err = ParseDerivationPath(dt, src); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%s %s: unexpected error %v", dt, src, err)
+		}
+	}
+}
+
+func TestBip48ScriptType(t *testing.T) {
+	dp, err := ParseDerivationPath(BIP48, `m/48'/0'/3'/2'/1/7`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dp.ScriptType() != ScriptP2WSH || dp.Account() != 3 || dp.Charge() != ChargeInternal || dp.AddressIndex().Index != 7 {
+		t.Errorf("unexpected levels %v", dp.Levels())
+	}
+
+	if dp.String() != `m/48'/0'/3'/2'/1/7` {
+		t.Errorf("unexpected path %s", dp)
+	}
+
+	if dp = NewDerivationPath(BIP48, 0, 1, ChargeExternal, AddressIndex{Index: 2}); dp.String() != `m/48'/0'/1'/1'/0/2` {
+		t.Errorf("unexpected path %s", dp)
+	}
+
+	if dp = NewDerivationPath(SLIP10, 501, 1, 0, AddressIndex{Index: 3}); dp.String() != `m/44'/501'/1'/0'/3'` || dp.Coin() != 501 || dp.Account() != 1 {
+		t.Errorf("unexpected path %s", dp)
+	}
+
+	if dp = NewDerivationPath(CUSTOM, 60, 0, ChargeInternal, AddressIndex{Index: 4}); dp.String() != `m/44'/60'/0'/1/4` || dp.Type() != CUSTOM {
+		t.Errorf("unexpected path %s", dp)
+	}
+
+	for _, src := range []string{`m/48'/0'/0'/0'/0/0`, `m/48'/0'/0'/1/0/0`, `m/48'/0'/0'/0/0`} {
+		if _, err = ParseDerivationPath(BIP48, src); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+}
+
+func TestZip32(t *testing.T) {
+	for src, expected := range map[string]string{
+		`m/32'/133'/0'`:    `m/32'/133'/0'`,
+		`m/32h/133h/4h/7h`: `m/32'/133'/4'/7'`,
+	} {
+		dp, err := ParseDerivationPath(ZIP32, src)
+		if err != nil {
+			t.Fatalf("%s: %s", src, err)
+		}
+
+		if dp.String() != expected || dp.Coin() != ZEC {
+			t.Errorf("%s: unexpected path %s", src, dp)
+		}
+	}
+
+	dp, _ := ParseDerivationPath(ZIP32, `m/32'/133'/4'/7'`)
+
+	if dp.Account() != 4 || dp.AddressIndex() != (AddressIndex{Index: 7, IsHardened: true}) {
+		t.Errorf("unexpected levels %v", dp.Levels())
+	}
+
+	for _, src := range []string{`m/32'/133'`, `m/32'/133'/0`, `m/32'/133'/0'/1`, `m/32'/133'/0'/1'/0'`, `m/44'/133'/0'`} {
+		if _, err := ParseDerivationPath(ZIP32, src); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+}
+
+func TestCip1852(t *testing.T) {
+	dp, err := ParseDerivationPath(CIP1852, `m/1852'/1815'/1'/2/0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dp.Coin() != ADA || dp.Account() != 1 || dp.Charge() != StakingRole {
+		t.Errorf("unexpected levels %v", dp.Levels())
+	}
+
+	for _, src := range []string{`m/1852'/1815'/0'/3/0`, `m/1852'/1815'/0'/0/0'`, `m/1852'/1816'/0'/0/0`, `m/44'/1815'/0'/0/0`} {
+		if _, err = ParseDerivationPath(CIP1852, src); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+}
+
+func TestSlip10(t *testing.T) {
+	for src, expected := range map[string]string{
+		`m/44'/148'/0'`:          `m/44'/148'/0'`,
+		`m/44h/501h/0h/0h`:       `m/44'/501'/0'/0'`,
+		`m/44h/501h`:             `m/44'/501'`,
+		`m/44h/501h/0h/0h/1h/2h`: `m/44'/501'/0'/0'/1'/2'`,
+	} {
+		dp, err := ParseDerivationPath(SLIP10, src)
+		if err != nil {
+			t.Fatalf("%s: %s", src, err)
+		}
+
+		if dp.String() != expected || !dp.IsHardened() {
+			t.Errorf("%s: unexpected path %s", src, dp)
+		}
+	}
+
+	dp, _ := ParseDerivationPath(SLIP10, `m/44'/148'/3'`)
+
+	if dp.Coin() != XLM || dp.Account() != 3 {
+		t.Errorf("unexpected levels %v", dp.Levels())
+	}
+
+	for _, src := range []string{`m`, `m/44'/501'/0'/0`, `m/44/501'`} {
+		if _, err := ParseDerivationPath(SLIP10, src); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+
+	for _, src := range []string{
+		`urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:bip44:dp:m/44h/501h/0h/0/0`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:custom:dp:m/44h/60h/0:aa:ed25519`,
+	} {
+		if _, err := ParseURN(src); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+
+	if _, err := ParseURN(`urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:bip44:dp:m/44h/501h/0h/0/0h:aa:secp256k1`); err != nil {
+		t.Error(err)
+	}
+
+	// default algorithm of Solana is ed25519, see compatibility note in README
+	if _, err := ParseURN(`urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:bip44:dp:m/44h/501h/0h/0/0`); !errors.Is(err, ErrBadDerivationPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err := ParseURN(`urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:bip44:dp:m/44h/501h/0h/0/0:aa:secp256k1`); err != nil {
+		t.Error(err)
+	}
+
+	address, err := ParseURN(`urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:slip10:dp:m/44h/501h/0h/0h`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address.String() != `urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:slip10:dp:m/44'/501'/0'/0'` {
+		t.Errorf("unexpected address %s", address)
+	}
+
+	if address.LongNSS() != `sv:2:nt:sol:ct:501:ci:mainnet-beta:dt:slip10:dp:m/44'/501'/0'/0':aa:ed25519:af:base58` {
+		t.Errorf("unexpected long nss %s", address.LongNSS())
+	}
+}
+
+func TestDerivationPathParentChild(t *testing.T) {
+	for _, src := range []string{
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/5`,
+		`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip48:dp:m/48'/0'/0'/2'/0/1`,
+		`urn:mhda:nt:zec:ct:133:ci:main:dt:zip32:dp:m/32'/133'/0'/3'`,
+		`urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:slip10:dp:m/44'/501'/0'/0'`,
+	} {
+		address, err := ParseURN(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path := address.DerivationPath()
+		levels := path.Levels()
+		last := levels[len(levels)-1]
+
+		parent, err := path.Parent()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		child, err := parent.Child(last.Index, last.IsHardened)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		restored := &Address{chain: address.Chain(), path: child}
+
+		if !Equal(address, restored) {
+			t.Errorf("%s: unexpected path %s %s", src, child.Type(), child)
+		}
+	}
+}
+
+func TestDerivationPathNavigation(t *testing.T) {
+	dp, err := ParseDerivationPath(BIP44, `m/44'/60'/0'/0/7`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		fn       func() (*DerivationPath, error)
+		expected string
+	}{
+		{dp.Next, `m/44'/60'/0'/0/8`},
+		{func() (*DerivationPath, error) { return dp.WithIndex(100) }, `m/44'/60'/0'/0/100`},
+		{func() (*DerivationPath, error) { return dp.WithAccount(3) }, `m/44'/60'/3'/0/7`},
+		{func() (*DerivationPath, error) { return dp.WithCharge(ChargeInternal) }, `m/44'/60'/0'/1/7`},
+		{dp.Parent, `m/44'/60'/0'/0`},
+	} {
+		result, err := tt.fn()
+		if err != nil {
+			t.Fatalf("%s: %s", tt.expected, err)
+		}
+
+		if result.String() != tt.expected {
+			t.Errorf("unexpected path %s, expected %s", result, tt.expected)
+		}
+	}
+
+	if dp.String() != `m/44'/60'/0'/0/7` {
+		t.Errorf("path is changed: %s", dp)
+	}
+
+	parent, _ := dp.Parent()
+
+	if parent.Type() != BIP44 || parent.Depth() != 4 || !parent.IsAncestorOf(dp) || dp.IsAncestorOf(parent) || dp.IsAncestorOf(dp) {
+		t.Errorf("unexpected parent %s %s", parent.Type(), parent)
+	}
+
+	child, err := parent.Child(9, false)
+	if err != nil || child.String() != `m/44'/60'/0'/0/9` || !parent.IsAncestorOf(child) {
+		t.Errorf("unexpected child %s %v", child, err)
+	}
+
+	for _, tt := range []struct {
+		fn     func() (*DerivationPath, error)
+		target error
+	}{
+		{func() (*DerivationPath, error) { return dp.WithIndex(1 << 31) }, ErrLevelOverflow},
+		{func() (*DerivationPath, error) { return dp.WithAccount(1 << 31) }, ErrLevelOverflow},
+		{func() (*DerivationPath, error) { return dp.WithCharge(2) }, ErrBadDerivationPath},
+		{func() (*DerivationPath, error) { return dp.Child(0, false) }, ErrBadDerivationPath},
+		{func() (*DerivationPath, error) { return parent.Child(1<<31, false) }, ErrLevelOverflow},
+	} {
+		if _, err = tt.fn(); !errors.Is(err, tt.target) {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+
+	last, _ := dp.WithIndex(maxLevelIndex)
+
+	if _, err = last.Next(); !errors.Is(err, ErrLevelOverflow) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	slip10, _ := ParseDerivationPath(SLIP10, `m/44'/501'/0'`)
+
+	if child, err = slip10.Child(0, true); err != nil || child.Type() != SLIP10 || child.String() != `m/44'/501'/0'/0'` {
+		t.Errorf("unexpected child %s %v", child, err)
+	}
+
+	if _, err = slip10.Child(0, false); !errors.Is(err, ErrBadDerivationPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if next, err := slip10.Next(); err != nil || next.String() != `m/44'/501'/1'` {
+		t.Errorf("unexpected next %s %v", next, err)
+	}
+
+	if next, err := slip10.WithIndex(5); err != nil || next.String() != `m/44'/501'/5'` {
+		t.Errorf("unexpected path %s %v", next, err)
+	}
+
+	if _, err = slip10.WithCharge(ChargeInternal); !errors.Is(err, ErrBadDerivationPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	custom, _ := ParseDerivationPath(CUSTOM, `m/0/3`)
+
+	if next, err := custom.Next(); err != nil || next.String() != `m/0/4` {
+		t.Errorf("unexpected next %s %v", next, err)
+	}
+
+	if next, err := parent.Next(); err != nil || next.Type() != BIP44 || next.String() != `m/44'/60'/0'/1` {
+		t.Errorf("unexpected next %s %v", next, err)
+	}
+
+	substrate, _ := ParseDerivationPath(SUBSTRATE, `//polkadot//0///secret`)
+
+	if child, err = substrate.Child(5, false); err != nil || child.String() != `//polkadot//0/5` || child.Password() != `secret` {
+		t.Errorf("unexpected child %s %v", child, err)
+	}
+
+	if parent, err = substrate.Parent(); err != nil || parent.String() != `//polkadot` || !parent.IsAncestorOf(substrate) {
+		t.Errorf("unexpected parent %s %v", parent, err)
+	}
+
+	if next, err := substrate.Next(); err != nil || next.String() != `//polkadot//1` {
+		t.Errorf("unexpected next %s %v", next, err)
+	}
+
+	if _, err = parent.Next(); !errors.Is(err, ErrBadDerivationPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err = NewDerivationPath(ROOT, 0, 0, 0, AddressIndex{}).Parent(); !errors.Is(err, ErrBadDerivationPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	var empty *DerivationPath
+
+	if _, err = empty.Next(); !errors.Is(err, ErrBadDerivationPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err = empty.Child(0, false); !errors.Is(err, ErrBadDerivationPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err = empty.Parent(); !errors.Is(err, ErrBadDerivationPath) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package go_mhda
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors, used as reason codes of ParseError
+var (
+	ErrInvalidPrefix       = errors.New("source string is not valid URN MHDA")
+	ErrBadRQFComponent     = errors.New("malformed r-, q- or f-component")
+	ErrMalformedNSS        = errors.New("malformed nss")
+	ErrUnknownComponent    = errors.New("unknown component")
+	ErrDuplicateComponent  = errors.New("duplicate component")
+	ErrComponentOrder      = errors.New("component is out of canonical order")
+	ErrMissingComponent    = errors.New("required component is not defined")
+	ErrBadComponentKey     = errors.New("wrong component key")
+	ErrComponentRegistered = errors.New("component is already registered")
+	ErrBadSpecVersion      = errors.New(`wrong "sv" value`)
+	ErrUnsupportedVersion  = errors.New("unsupported spec version")
+	ErrBadTemplate         = errors.New("malformed template")
+	ErrMissingPlaceholder  = errors.New("placeholder value is not defined")
+	ErrUnknownPlaceholder  = errors.New("unknown placeholder")
+	ErrPlaceholderRange    = errors.New("placeholder value is out of range")
+	ErrBadRange            = errors.New("malformed range")
+	ErrLineTooLong         = errors.New("stream line is too long")
+	ErrBadExtension        = errors.New("wrong extension component value")
+	ErrMissingNetworkType  = errors.New(`"nt" not defined`)
+	ErrUnknownNetworkType  = errors.New("undefined network type")
+	ErrMissingCoinType     = errors.New(`"ct" required`)
+	ErrBadCoinType         = errors.New(`cannot parse "ct"`)
+	ErrCoinTypeMismatch    = errors.New(`"ct" does not match coin level of "dp"`)
+	ErrMissingChainId      = errors.New(`"ci" required`)
+	ErrBadDerivationType   = errors.New(`wrong "dt" value`)
+	ErrBadDerivationPath   = errors.New(`wrong "dp" value`)
+	ErrLevelOverflow       = errors.New("path level is out of range")
+	ErrUnknownAlgorithm    = errors.New(`incorrect "aa" param`)
+	ErrBadScheme           = errors.New("malformed derivation scheme")
+	ErrUnknownScheme       = errors.New("unknown derivation scheme")
+	ErrSchemeRegistered    = errors.New("derivation scheme is already registered")
+	ErrSchemeMismatch      = errors.New(`"dp" does not follow derivation scheme`)
+)
+
+// ParseError describes failure of URN, NSS or single component parsing
+type ParseError struct {
+	// Component is component key, e.g. "ct", or empty for errors, not related to any component
+	Component string
+	// Offset is byte offset of failure in source string, for missing components it points to NSS start
+	Offset int
+	// Value is raw value of component or wrong symbol
+	Value string
+	// Err is reason code, one of Err* sentinels
+	Err error
+}
+
+func newParseError(component string, offset int, value string, err error) *ParseError {
+	return &ParseError{
+		Component: component,
+		Offset:    offset,
+		Value:     value,
+		Err:       err,
+	}
+}
+
+func (e *ParseError) Error() string {
+	result := e.Err.Error()
+
+	if e.Component != `` {
+		result += fmt.Sprintf(`, component "%s"`, e.Component)
+	}
+
+	if e.Value != `` {
+		result += fmt.Sprintf(`, value %q`, e.Value)
+	}
+
+	return result + fmt.Sprintf(`, pos %d`, e.Offset)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// extensionError is error of extension validator, it matches both ErrBadExtension and cause
+type extensionError struct {
+	cause error
+}
+
+func (e *extensionError) Error() string {
+	return ErrBadExtension.Error() + `: ` + e.cause.Error()
+}
+
+func (e *extensionError) Is(target error) bool {
+	return target == ErrBadExtension
+}
+
+func (e *extensionError) Unwrap() error {
+	return e.cause
+}
+
+// withOffset shifts ParseError position by offset of parsed substring
+func withOffset(err error, offset int) error {
+	var parseErr *ParseError
+
+	if errors.As(err, &parseErr) {
+		parseErr.Offset += offset
+	}
+
+	return err
+}
@@ -0,0 +1,108 @@
+package go_mhda
+
+import "strings"
+
+const upperHex = "0123456789ABCDEF"
+
+// isNSSChar reports whether symbol can be used in component value without encoding.
+// RFC 3986: unreserved / sub-delims / "@" / "/", ":" is reserved as components separator
+func isNSSChar(c byte) bool {
+	// ASCII checks instead regexp for performance
+	return (c >= 48 && c <= 57) || // 48-57  [0-9]
+		(c >= 97 && c <= 122) || // 97-122 [a-z]
+		(c >= 65 && c <= 90) || // 65-90 [A-Z]
+		(c >= 38 && c <= 47) || // 38-47 [&'()*+,-./]
+		c == 33 || // 33 [!]
+		c == 36 || // 36 [$]
+		c == 59 || // 59 [;]
+		c == 61 || // 61 [=]
+		c == 64 || // 64 [@]
+		c == 95 || // 95 [_]
+		c == 126 // 126 [~]
+}
+
+func isHex(c byte) bool {
+	return (c >= 48 && c <= 57) || (c >= 65 && c <= 70) || (c >= 97 && c <= 102)
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= 97:
+		return c - 97 + 10
+	case c >= 65:
+		return c - 65 + 10
+	}
+	return c - 48
+}
+
+// invalidComponentIndex returns position of first wrong symbol in raw component value,
+// or -1 when value is valid
+func invalidComponentIndex(src string) int {
+	for i := 0; i < len(src); i++ {
+		if src[i] == 37 { // 37 [%]
+			if i+2 >= len(src) || !isHex(src[i+1]) || !isHex(src[i+2]) {
+				return i
+			}
+			i += 2
+			continue
+		}
+		if !isNSSChar(src[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeComponent decodes percent-encoded value, which must be checked
+// by invalidComponentIndex before
+func unescapeComponent(src string) string {
+	if strings.IndexByte(src, '%') < 0 {
+		return src
+	}
+
+	var sb strings.Builder
+
+	sb.Grow(len(src))
+
+	for i := 0; i < len(src); i++ {
+		if src[i] == '%' {
+			sb.WriteByte(unhex(src[i+1])<<4 | unhex(src[i+2]))
+			i += 2
+		} else {
+			sb.WriteByte(src[i])
+		}
+	}
+
+	return sb.String()
+}
+
+// escapeComponent encodes component value in canonical form, with uppercase hex digits
+func escapeComponent(src string) string {
+	var count int
+
+	for i := 0; i < len(src); i++ {
+		if !isNSSChar(src[i]) {
+			count++
+		}
+	}
+
+	if count == 0 {
+		return src
+	}
+
+	var sb strings.Builder
+
+	sb.Grow(len(src) + 2*count)
+
+	for i := 0; i < len(src); i++ {
+		if isNSSChar(src[i]) {
+			sb.WriteByte(src[i])
+		} else {
+			sb.WriteByte('%')
+			sb.WriteByte(upperHex[src[i]>>4])
+			sb.WriteByte(upperHex[src[i]&15])
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,182 @@
+package go_mhda
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// prefixExperimental marks unregistered extension components, which are kept without validation
+const prefixExperimental = `x-`
+
+// ComponentValidator checks value of extension component
+type ComponentValidator func(value string) error
+
+var (
+	extensionsMu    sync.RWMutex
+	extensionsIndex = map[string]ComponentValidator{
+		compScheme: validateSchemeName,
+	}
+)
+
+// RegisterComponent registers extension component key, e.g. "lb" for labels, with optional validator.
+// Registered components are stored on Address and written by NSS() after defined components,
+// sorted by key. Keys with "x-" prefix are accepted without registration, keys of defined
+// components and legacy "ad" are rejected
+func RegisterComponent(key string, validator ComponentValidator) error {
+	key = strings.ToLower(key)
+
+	if !isComponentKey(key) || componentIndex(key) >= 0 || key == compLegacyAddressFormat ||
+		strings.HasPrefix(key, prefixExperimental) {
+		return newParseError(key, 0, ``, ErrBadComponentKey)
+	}
+
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+
+	if _, ok := extensionsIndex[key]; ok {
+		return newParseError(key, 0, ``, ErrComponentRegistered)
+	}
+
+	extensionsIndex[key] = validator
+
+	return nil
+}
+
+// UnregisterComponent removes extension component, registered by RegisterComponent
+func UnregisterComponent(key string) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+
+	delete(extensionsIndex, strings.ToLower(key))
+}
+
+// isComponentKey checks key syntax: [a-z][a-z0-9-]*
+func isComponentKey(key string) bool {
+	if key == `` || key[0] < 97 || key[0] > 122 {
+		return false
+	}
+
+	for i := 1; i < len(key); i++ {
+		if !((key[i] >= 97 && key[i] <= 122) || (key[i] >= 48 && key[i] <= 57) || key[i] == 45) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isExperimentalKey checks, that key has "x-" prefix in any case
+func isExperimentalKey(key string) bool {
+	return len(key) >= len(prefixExperimental) && strings.EqualFold(key[:len(prefixExperimental)], prefixExperimental)
+}
+
+// extensionKey returns normalized key of extension component. Registered keys are lowercased,
+// keys with "x-" prefix are kept as is and can contain any NSS symbols, except ":".
+// It returns ErrBadComponentKey for malformed keys and ErrUnknownComponent for unregistered keys
+func extensionKey(key string) (string, error) {
+	if isExperimentalKey(key) {
+		if len(key) == len(prefixExperimental) || invalidComponentIndex(key) >= 0 {
+			return ``, ErrBadComponentKey
+		}
+		return key, nil
+	}
+
+	key = strings.ToLower(key)
+
+	if !isComponentKey(key) {
+		return ``, ErrBadComponentKey
+	}
+
+	extensionsMu.RLock()
+	_, ok := extensionsIndex[key]
+	extensionsMu.RUnlock()
+
+	if !ok {
+		return ``, ErrUnknownComponent
+	}
+
+	return key, nil
+}
+
+func validateExtension(key, value string) error {
+	extensionsMu.RLock()
+	validator := extensionsIndex[key]
+	extensionsMu.RUnlock()
+
+	if validator == nil {
+		return nil
+	}
+
+	if err := validator(value); err != nil {
+		return newParseError(key, 0, value, &extensionError{cause: err})
+	}
+
+	return nil
+}
+
+// Extension returns value of extension component, keys with "x-" prefix are case-sensitive
+func (a *Address) Extension(key string) (string, bool) {
+	if !isExperimentalKey(key) {
+		key = strings.ToLower(key)
+	}
+	value, ok := a.extensions[key]
+	return value, ok
+}
+
+// Extensions returns sorted keys of defined extension components
+func (a *Address) Extensions() []string {
+	keys := make([]string, 0, len(a.extensions))
+
+	for key := range a.extensions {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SetExtension sets value of registered or "x-" prefixed extension component,
+// empty value removes component
+func (a *Address) SetExtension(key, value string) error {
+	extKey, err := extensionKey(key)
+
+	if err != nil {
+		return newParseError(key, 0, ``, err)
+	}
+
+	key = extKey
+
+	// scheme names are case-insensitive, see LookupScheme
+	if key == compScheme {
+		value = strings.ToLower(value)
+	}
+
+	if value == `` {
+		delete(a.extensions, key)
+		return nil
+	}
+
+	if err = validateExtension(key, value); err != nil {
+		return err
+	}
+
+	if a.extensions == nil {
+		a.extensions = map[string]string{}
+	}
+
+	a.extensions[key] = value
+
+	return nil
+}
+
+func (a *Address) extensionsNSS() string {
+	var result string
+
+	for _, key := range a.Extensions() {
+		result += `:` + key + `:` + escapeComponent(a.extensions[key])
+	}
+
+	return result
+}
@@ -3,7 +3,6 @@ package go_mhda
 import (
 	"crypto/sha1"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -15,10 +14,16 @@ type MHDA interface {
 	DerivationPath() *DerivationPath
 	Algorithm() Algorithm
 	Format() Format
+	Prefix() string
+	Suffix() string
 	NSS() string
+	LongNSS() string
 	String() string
+	LongString() string
 	Hash() string
 	NSSHash() string
+	RQF() RQFComponents
+	Extension(key string) (string, bool)
 }
 
 type Address struct {
@@ -28,6 +33,9 @@ type Address struct {
 	addressFormat    Format
 	addressPrefix    string
 	addressSuffix    string
+	extensions       map[string]string
+	rqf              RQFComponents
+	specVersion      SpecVersion
 }
 
 // NewAddress  add optional params: aa, af, ap, as
@@ -35,51 +43,87 @@ func NewAddress(chain *Chain, path *DerivationPath, params ...string) *Address {
 	return &Address{chain: chain, path: path}
 }
 
-func parseAddress(m map[string]string) (MHDA, error) {
+// setComponents fills address from parsed components. Chain and DerivationPath,
+// referenced by address, are reused
+func (a *Address) setComponents(m *nssComponents) error {
 	var err error
 
-	chain, err := parseChain(m)
+	if a.chain == nil {
+		a.chain = &Chain{}
+	}
 
+	err = a.chain.setComponents(m)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if a.path == nil {
+		a.path = &DerivationPath{}
+	} else {
+		*a.path = DerivationPath{levels: a.path.levels[:0]}
 	}
 
-	mhda := &Address{
-		chain: chain,
+	a.addressAlgorithm = ``
+	a.addressFormat = ``
+	a.addressPrefix = ``
+	a.addressSuffix = ``
+	a.rqf = RQFComponents{}
+	a.specVersion = m.version
+
+	err = a.SetDerivationType(m.values[indexDerivationType].value)
+	if err != nil {
+		return withOffset(err, m.values[indexDerivationType].offset)
 	}
 
-	err = mhda.SetDerivationType(m[compDerivationType])
+	err = a.SetDerivationPath(m.values[indexDerivationPath].value)
 	if err != nil {
-		return nil, err
+		return withOffset(err, m.values[indexDerivationPath].offset)
 	}
 
-	// TODO: Add dp validation
-	err = mhda.SetDerivationPath(m[compDerivationPath])
+	err = a.SetAddressAlgorithm(m.values[indexAddressAlgorithm].value)
 	if err != nil {
-		return nil, err
+		return withOffset(err, m.values[indexAddressAlgorithm].offset)
 	}
 
-	err = mhda.SetAddressAlgorithm(m[compAddressAlgorithm])
+	err = a.validateAlgorithm()
 	if err != nil {
-		return nil, err
+		return withOffset(err, m.values[indexDerivationPath].offset)
 	}
 
-	err = mhda.SetAddressFormat(m[compAddressFormat])
+	err = a.SetAddressFormat(m.values[indexAddressFormat].value)
 	if err != nil {
-		return nil, err
+		return withOffset(err, m.values[indexAddressFormat].offset)
 	}
 
-	err = mhda.SetAddressPrefix(m[compAddressPrefix])
+	err = a.SetAddressPrefix(m.values[indexAddressPrefix].value)
 	if err != nil {
-		return nil, err
+		return withOffset(err, m.values[indexAddressPrefix].offset)
 	}
 
-	err = mhda.SetAddressSuffix(m[compAddressSuffix])
+	err = a.SetAddressSuffix(m.values[indexAddressSuffix].value)
 	if err != nil {
-		return nil, err
+		return withOffset(err, m.values[indexAddressSuffix].offset)
 	}
 
-	return mhda, nil
+	for key := range a.extensions {
+		delete(a.extensions, key)
+	}
+
+	for _, ext := range m.extensions {
+		err = a.SetExtension(ext.key, ext.value)
+		if err != nil {
+			return withOffset(err, ext.offset)
+		}
+
+		if ext.key == compScheme {
+			err = a.validateScheme()
+			if err != nil {
+				return withOffset(err, ext.offset)
+			}
+		}
+	}
+
+	return nil
 }
 
 func (a *Address) Chain() *Chain {
@@ -102,6 +146,30 @@ func (a *Address) Format() Format {
 	return a.addressFormat
 }
 
+func (a *Address) Prefix() string {
+	return a.addressPrefix
+}
+
+func (a *Address) Suffix() string {
+	return a.addressSuffix
+}
+
+// RQF returns r-, q- and f-components of URN
+func (a *Address) RQF() RQFComponents {
+	return a.rqf
+}
+
+// SetRQF sets r-, q- and f-components, which must be parsed back by ParseURN as is
+func (a *Address) SetRQF(rqf RQFComponents) error {
+	if err := rqf.validate(); err != nil {
+		return err
+	}
+
+	a.rqf = rqf
+
+	return nil
+}
+
 func (a *Address) SetDerivationType(dt string) error {
 	dt = strings.TrimSpace(dt)
 	dt = strings.ToLower(dt)
@@ -112,7 +180,7 @@ func (a *Address) SetDerivationType(dt string) error {
 
 	if dt != `` {
 		if _, ok := derivationIndex[DerivationType(dt)]; !ok {
-			return errors.New(fmt.Sprintf(`"dt" param has wrong value "%s"`, dt))
+			return newParseError(compDerivationType, 0, dt, ErrBadDerivationType)
 		}
 
 		a.path.derivationType = DerivationType(dt)
@@ -128,17 +196,11 @@ func (a *Address) SetDerivationPath(dp string) error {
 		return nil
 	}
 
-	rx, ok := derivationIndex[a.path.derivationType]
-
-	if !ok {
-		return errors.New(`incorrect "dp" param`)
-	}
-
 	dp = strings.TrimSpace(dp)
-	dp = strings.ToLower(dp)
 
-	if !rx.MatchString(dp) {
-		return errors.New(fmt.Sprintf(`"dp" param has wrong value "%s"`, dp))
+	// junctions are case sensitive
+	if a.path.derivationType != SUBSTRATE {
+		dp = strings.ToLower(dp)
 	}
 
 	return a.path.ParsePath(dp)
@@ -147,14 +209,13 @@ func (a *Address) SetDerivationPath(dp string) error {
 func (a *Address) SetCoinType(ct string) error {
 	ct = strings.TrimSpace(ct)
 
-	// TODO: Check coin type extraction from derivation path??? subnets???
 	if ct == `` {
-		return errors.New(fmt.Sprintf(`"ct" required for "ct=%s"`, a.chain.networkType))
+		return newParseError(compCoinType, 0, ``, ErrMissingCoinType)
 	}
 
 	coinType, err := strconv.ParseUint(ct, 0, 32)
 	if err != nil {
-		return errors.New(`cannot parse "ct"`)
+		return newParseError(compCoinType, 0, ct, ErrBadCoinType)
 	}
 
 	a.chain.coinType = CoinType(coinType)
@@ -167,15 +228,10 @@ func (a *Address) SetAddressAlgorithm(aa string) error {
 	aa = strings.ToLower(aa)
 	if aa == `` {
 		// set default
-		switch a.chain.networkType {
-		case Bitcoin, EthereumVM, AvalancheVM, TronVM, Cosmos:
-			a.addressAlgorithm = Secp256k1
-		case Solana:
-			a.addressAlgorithm = Ed25519
-		}
+		a.addressAlgorithm = a.defaults().algorithm
 	} else {
 		if _, ok := indexAlgorithms[Algorithm(aa)]; !ok {
-			return errors.New(`incorrect "aa" param`)
+			return newParseError(compAddressAlgorithm, 0, aa, ErrUnknownAlgorithm)
 		}
 		a.addressAlgorithm = Algorithm(aa)
 	}
@@ -183,53 +239,130 @@ func (a *Address) SetAddressAlgorithm(aa string) error {
 	return nil
 }
 
+// validateAlgorithm checks derivation path levels, supported by address algorithm.
+// SLIP-10 defines only hardened derivation for ed25519
+func (a *Address) validateAlgorithm() error {
+	if a.addressAlgorithm == Ed25519 && !a.path.IsHardened() {
+		return newParseError(compDerivationPath, 0, a.path.String(), ErrBadDerivationPath)
+	}
+	return nil
+}
+
 func (a *Address) SetAddressFormat(af string) error {
 	af = strings.TrimSpace(af)
+	af = strings.ToLower(af)
 	if af != `` {
 		a.addressFormat = Format(af)
+	} else {
+		a.addressFormat = a.defaults().format
 	}
 	return nil
 }
 
+// SetAddressPrefix sets prefix as is, spaces are kept, empty prefix sets default
 func (a *Address) SetAddressPrefix(ap string) error {
-	ap = strings.TrimSpace(ap)
 	if ap != `` {
 		a.addressPrefix = ap
+	} else {
+		a.addressPrefix = a.defaults().prefix
 	}
 
 	return nil
 }
 
+// SetAddressSuffix sets suffix as is, spaces are kept
 func (a *Address) SetAddressSuffix(as string) error {
-	as = strings.TrimSpace(as)
 	if as != `` {
 		a.addressSuffix = as
 	}
 	return nil
 }
 
+// defaults returns default address params for network and derivation types of address
+func (a *Address) defaults() addressDefaults {
+	return defaultsFor(a.chain, a.path)
+}
+
 func (a *Address) String() string {
-	return fmt.Sprintf(`urn:mhda:%s`, a.NSS())
+	return fmt.Sprintf(`urn:mhda:%s%s`, a.NSS(), a.rqf.String())
 }
 
+// LongString returns URN in long form, see LongNSS
+func (a *Address) LongString() string {
+	return fmt.Sprintf(`urn:mhda:%s%s`, a.LongNSS(), a.rqf.String())
+}
+
+// NSS returns NSS in canonical short form, see Canonicalize
 func (a *Address) NSS() string {
-	result := fmt.Sprintf(`nt:%s`, a.chain.networkType)
+	return a.nss(false)
+}
+
+// LongNSS returns NSS with explicitly defined "sv", "aa", "af" and "ap" components, also
+// when they have default values
+func (a *Address) LongNSS() string {
+	return a.nss(true)
+}
+
+func (a *Address) nss(isLong bool) string {
+	var result string
 
-	if a.path.derivationType != ROOT {
-		result += fmt.Sprintf(`:dt:%s:dp:%s`, a.path.derivationType, a.path.String())
+	if isLong {
+		result = fmt.Sprintf(`sv:%d:`, CurrentSpecVersion)
 	}
 
-	result += fmt.Sprintf(`:ct:%d:ci:%s`, a.chain.coinType, a.chain.chainId)
+	result += a.chain.String()
+
+	if dt := a.path.Type(); dt != ROOT {
+		result += fmt.Sprintf(`:dt:%s:dp:%s`, dt, escapeComponent(a.path.String()))
+	}
 
-	// TODO: add full mode
-	// TODO: use additional params, when address has non-default values
+	var (
+		defaults  = a.defaults()
+		algorithm = a.addressAlgorithm
+		format    = a.addressFormat
+		prefix    = a.addressPrefix
+	)
+
+	if isLong {
+		if algorithm == `` {
+			algorithm = defaults.algorithm
+		}
+		if format == `` {
+			format = defaults.format
+		}
+		if prefix == `` {
+			prefix = defaults.prefix
+		}
+	}
+
+	if algorithm != `` && (isLong || algorithm != defaults.algorithm) {
+		result += `:aa:` + escapeComponent(string(algorithm))
+	}
+
+	if format != `` && (isLong || format != defaults.format) {
+		result += `:af:` + escapeComponent(string(format))
+	}
+
+	if prefix != `` && (isLong || prefix != defaults.prefix) {
+		result += `:ap:` + escapeComponent(prefix)
+	}
+
+	if a.addressSuffix != `` {
+		result += `:as:` + escapeComponent(a.addressSuffix)
+	}
+
+	if len(a.extensions) > 0 {
+		result += a.extensionsNSS()
+	}
 
 	return result
 }
 
+// Hash returns hash of assigned name, r-, q- and f-components are ignored
+// according RFC 8141 equivalence rules
 func (a *Address) Hash() string {
 	h := sha1.New()
-	h.Write([]byte(a.String()))
+	h.Write([]byte(prefixMHDA + a.NSS()))
 	return hex.EncodeToString(h.Sum(nil))
 }
 
@@ -1,6 +1,9 @@
 package go_mhda
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 var (
 	uriMHDA = []string{
@@ -9,7 +12,7 @@ var (
 		`urn:mhda:nt:evm:ct:60:ci:1`,
 		`urn:mhda:nt:btc:dt:bip44:dp:m/44'/0'/0'/0/0:ct:0:ci:bitcoin_testnet`,
 		`urn:mhda:nt:btc:dt:bip44:dp:m/44'/0'/1'/0/1:ct:0:ci:bitcoin:aa:secp256k1:af:p2pkh:ap:1`,
-		//`urn:mhda:nt:btc:dt:bip84:dp:m/84'/0'/2'/0/2:ct:0:ci:bitcoin:aa:secp256k1:af:p2pkh:ap:bc1q`,
+		`urn:mhda:nt:btc:dt:bip84:dp:m/84'/0'/2'/0/2:ct:0:ci:bitcoin:aa:secp256k1:af:p2pkh:ap:bc1q`,
 	}
 )
 
@@ -40,14 +43,660 @@ func TestParseNSS(t *testing.T) {
 	}
 }
 
+func TestParseURNInto(t *testing.T) {
+	addr := &Address{}
+
+	for i := range uriMHDA {
+		if err := ParseURNInto(uriMHDA[i], addr); err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := ParseURN(uriMHDA[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr.String() != expected.String() {
+			t.Fatal("mismatch result", addr.String(), expected.String())
+		}
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := ParseURNInto(uriMHDA[0], addr); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Fatalf("expected zero allocations, got %v", allocs)
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		ParseURN(uriMHDA[0])
 	}
 }
 
+func BenchmarkParseInto(b *testing.B) {
+	addr := &Address{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ParseURNInto(uriMHDA[0], addr)
+	}
+}
+
 func BenchmarkParseRx(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		ParseURNRx(uriMHDA[0])
 	}
 }
+
+func TestParseRQF(t *testing.T) {
+	src := `urn:mhda:nt:evm:ct:60:ci:0x1?+resolver?=label=cold&confirmations=6#main`
+
+	addr, err := ParseURN(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rqf := addr.RQF()
+
+	if rqf.Resolution != `resolver` || rqf.Query != `label=cold&confirmations=6` || rqf.Fragment != `main` {
+		t.Fatalf("unexpected rqf components: %+v", rqf)
+	}
+
+	values, err := rqf.QueryValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values.Get(`confirmations`) != `6` {
+		t.Fatalf("unexpected q-component value %q", values.Get(`confirmations`))
+	}
+
+	if addr.NSS() != `nt:evm:ct:60:ci:0x1` {
+		t.Fatalf("rqf components leaked to nss: %s", addr.NSS())
+	}
+
+	if addr.String() != src {
+		t.Fatal("mismatch result", addr.String(), src)
+	}
+
+	plain, err := ParseURN(`urn:mhda:nt:evm:ct:60:ci:1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plain.Hash() != addr.Hash() || plain.NSSHash() != addr.NSSHash() {
+		t.Fatal("rqf components must not affect hash")
+	}
+
+	for _, rqf := range []RQFComponents{
+		{Resolution: `a?+b`, Query: `q=a?=b&c=?+`, Fragment: `f?=#?+`},
+		{Resolution: `a?`, Query: `=x`},
+		{Query: `a?=b`},
+		{Fragment: `#`},
+	} {
+		if err = plain.(*Address).SetRQF(rqf); err != nil {
+			t.Fatal(err)
+		}
+
+		parsed, err := ParseURN(plain.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parsed.RQF() != rqf {
+			t.Fatalf("unexpected rqf components of %s: %+v", plain, parsed.RQF())
+		}
+	}
+
+	for _, rqf := range []RQFComponents{
+		{Resolution: `a?=b`},
+		{Resolution: `a#b`},
+		{Query: `a#b`},
+	} {
+		if err = plain.(*Address).SetRQF(rqf); !errors.Is(err, ErrBadRQFComponent) {
+			t.Fatalf("expected %v for %+v, got %v", ErrBadRQFComponent, rqf, err)
+		}
+	}
+
+	for _, invalid := range []string{
+		`urn:mhda:nt:evm:ct:60:ci:1?=`,
+		`urn:mhda:nt:evm:ct:60:ci:1?+`,
+		`urn:mhda:nt:evm:ct:60:ci:1?label`,
+	} {
+		if _, err = ParseURN(invalid); err == nil {
+			t.Fatalf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		src       string
+		err       error
+		component string
+		offset    int
+	}{
+		{`urn:mhdx:nt:evm:ct:60:ci:1`, ErrInvalidPrefix, ``, 0},
+		{`urn:mhda:ct:60:ci:1`, ErrMissingNetworkType, compNetworkType, 9},
+		{`urn:mhda:nt:evm:ct:eth:ci:1`, ErrBadCoinType, compCoinType, 19},
+		{`urn:mhda:nt:evm:ct:60`, ErrMissingChainId, compChainId, 9},
+		{`urn:mhda:nt:evm:dt:bip99:dp:m/0:ct:60:ci:1`, ErrBadDerivationType, compDerivationType, 19},
+		{`urn:mhda:nt:evm:dt:bip44:dp:m/44'/60'/0'/0:ct:60:ci:1`, ErrBadDerivationPath, compDerivationPath, 42},
+		{`urn:mhda:nt:evm:ct:60:ci:1:aa:rsa`, ErrUnknownAlgorithm, compAddressAlgorithm, 30},
+		{`urn:mhda:nt:evm:ct:60:ci:1 2`, ErrMalformedNSS, compChainId, 26},
+	}
+
+	for _, c := range cases {
+		_, err := ParseURN(c.src)
+
+		if !errors.Is(err, c.err) {
+			t.Fatalf("expected %v for %s, got %v", c.err, c.src, err)
+		}
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected ParseError for %s, got %T", c.src, err)
+		}
+
+		if parseErr.Component != c.component || parseErr.Offset != c.offset {
+			t.Fatalf("unexpected error position for %s: %s", c.src, err)
+		}
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	long := `urn:mhda:nt:evm:ct:60:ci:1:dt:bip44:dp:m/44'/60'/0'/0/1:aa:secp256k1:af:hex:ap:0x`
+
+	if _, err := ParseURNWithOptions(long, StrictParseOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		src  string
+		opts ParseOptions
+		err  error
+	}{
+		{long + `:lb:cold`, ParseOptions{RejectUnknown: true}, ErrUnknownComponent},
+		{long + `:ci:2`, ParseOptions{RejectDuplicates: true}, ErrDuplicateComponent},
+		{`urn:mhda:nt:evm:ci:1:ct:60`, ParseOptions{RequireCanonicalOrder: true}, ErrComponentOrder},
+		{`urn:mhda:nt:evm:ct:60:ci:1:aa:secp256k1`, ParseOptions{RequireLongForm: true}, ErrMissingComponent},
+		{`urn:mhda:nt:evm:ct:60:ci:1:dt:bip44:dp:m/44H/60H/0H/0/1`, ParseOptions{CaseSensitiveHardened: true}, ErrBadDerivationPath},
+	}
+
+	for _, c := range cases {
+		if _, err := ParseURN(c.src); err != nil {
+			t.Fatalf("lenient mode must accept %s: %s", c.src, err)
+		}
+
+		if _, err := ParseURNWithOptions(c.src, c.opts); !errors.Is(err, c.err) {
+			t.Fatalf("expected %v for %s, got %v", c.err, c.src, err)
+		}
+	}
+
+	addr, err := ParseURN(long + `:ci:2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr.Chain().ChainId() != `1` {
+		t.Fatalf("first duplicate must be used in lenient mode, got %s", addr.Chain().ChainId())
+	}
+
+	legacy, err := ParseURN(`urn:mhda:nt:btc:ct:0:ci:bitcoin:ad:p2wpkh`)
+	if err != nil || legacy.Format() != P2WPKH {
+		t.Fatalf("legacy address format key must be accepted: %v", err)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	cases := []struct {
+		src       string
+		canonical string
+	}{
+		{
+			`URN:MHDA:nt:evm:dt:bip44:dp:m/44h/60H/0'/0/1:ct:60:ci:1:aa:secp256k1:af:hex:ap:0x`,
+			`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1`,
+		},
+		{
+			`urn:mhda:NT:evm:CT:0x3c:CI:0xA86A:DT:BIP44:DP:m/44'/60'/00'/0/007'`,
+			`urn:mhda:nt:evm:ct:60:ci:0xa86a:dt:bip44:dp:m/44'/60'/0'/0/7'`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip44:dp:m/44h/0h/0h/0/0:aa:secp256k1:af:p2pkh:ap:1`,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip44:dp:m/44'/0'/0'/0/0`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip84:dp:m/84h/0h/0h/0/0:af:p2pkh:ap:1?=label=cold`,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip84:dp:m/84'/0'/0'/0/0:af:p2pkh:ap:1`,
+		},
+		{
+			`urn:mhda:nt:cosmos:ct:118:ci:cosmoshub-4:ap:cosmos:as:memo%3a1`,
+			`urn:mhda:nt:cosmos:ct:118:ci:cosmoshub-4:ap:cosmos:as:memo%3A1`,
+		},
+		{
+			`urn:mhda:nt:EVM:ct:60:ci:1:dt:bip44:dp:m/44h/60h/0h/0/1:aa:secp256k1:ap:0x`,
+			`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1`,
+		},
+	}
+
+	for _, c := range cases {
+		canonical, err := Canonicalize(c.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if canonical != c.canonical {
+			t.Fatal("mismatch result", canonical, c.canonical)
+		}
+
+		again, err := Canonicalize(canonical)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if again != canonical {
+			t.Fatal("canonical form is not stable", again, canonical)
+		}
+	}
+}
+
+func TestLongForm(t *testing.T) {
+	cases := []struct {
+		src   string
+		short string
+		long  string
+	}{
+		{
+			`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/0`,
+			`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/0`,
+			`urn:mhda:sv:2:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/0:aa:secp256k1:af:hex:ap:0x`,
+		},
+		{
+			`urn:mhda:nt:avm:ct:9000:ci:0x1:dt:bip44:dp:m/44h/9000h/0h/0/0:ap:P-avax`,
+			`urn:mhda:nt:avm:ct:9000:ci:0x1:dt:bip44:dp:m/44'/9000'/0'/0/0:ap:P-avax`,
+			`urn:mhda:sv:2:nt:avm:ct:9000:ci:0x1:dt:bip44:dp:m/44'/9000'/0'/0/0:aa:secp256k1:af:hex:ap:P-avax`,
+		},
+		{
+			`urn:mhda:nt:cosmos:ct:118:ci:axelar-dojo-1:ap:axelar:as:memo%20text`,
+			`urn:mhda:nt:cosmos:ct:118:ci:axelar-dojo-1:ap:axelar:as:memo%20text`,
+			`urn:mhda:sv:2:nt:cosmos:ct:118:ci:axelar-dojo-1:aa:secp256k1:af:bech32:ap:axelar:as:memo%20text`,
+		},
+		{
+			`urn:mhda:nt:cosmos:ct:118:ci:axelar-dojo-1:ap:%20axelar%20:as:%20memo%20`,
+			`urn:mhda:nt:cosmos:ct:118:ci:axelar-dojo-1:ap:%20axelar%20:as:%20memo%20`,
+			`urn:mhda:sv:2:nt:cosmos:ct:118:ci:axelar-dojo-1:aa:secp256k1:af:bech32:ap:%20axelar%20:as:%20memo%20`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip49:dp:m/49h/0h/0h/1/5:af:P2SH-P2WPKH:ap:3`,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip49:dp:m/49'/0'/0'/1/5`,
+			`urn:mhda:sv:2:nt:btc:ct:0:ci:bitcoin:dt:bip49:dp:m/49'/0'/0'/1/5:aa:secp256k1:af:p2sh-p2wpkh:ap:3`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:1:ci:testnet:dt:bip49:dp:m/49h/1h/0h/0/0`,
+			`urn:mhda:nt:btc:ct:1:ci:testnet:dt:bip49:dp:m/49'/1'/0'/0/0`,
+			`urn:mhda:sv:2:nt:btc:ct:1:ci:testnet:dt:bip49:dp:m/49'/1'/0'/0/0:aa:secp256k1:af:p2sh-p2wpkh:ap:2`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip86:dp:m/86h/0h/0h/0/0:aa:secp256k1:af:p2tr:ap:bc1p`,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip86:dp:m/86'/0'/0'/0/0`,
+			`urn:mhda:sv:2:nt:btc:ct:0:ci:bitcoin:dt:bip86:dp:m/86'/0'/0'/0/0:aa:secp256k1:af:p2tr:ap:bc1p`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:1:ci:signet:dt:bip86:dp:m/86h/1h/2h/1/9`,
+			`urn:mhda:nt:btc:ct:1:ci:signet:dt:bip86:dp:m/86'/1'/2'/1/9`,
+			`urn:mhda:sv:2:nt:btc:ct:1:ci:signet:dt:bip86:dp:m/86'/1'/2'/1/9:aa:secp256k1:af:p2tr:ap:tb1p`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip48:dp:m/48h/0h/0h/1h/0/0`,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip48:dp:m/48'/0'/0'/1'/0/0`,
+			`urn:mhda:sv:2:nt:btc:ct:0:ci:bitcoin:dt:bip48:dp:m/48'/0'/0'/1'/0/0:aa:secp256k1:af:p2sh-p2wsh:ap:3`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:1:ci:testnet:dt:bip48:dp:m/48h/1h/0h/2h/1/3`,
+			`urn:mhda:nt:btc:ct:1:ci:testnet:dt:bip48:dp:m/48'/1'/0'/2'/1/3`,
+			`urn:mhda:sv:2:nt:btc:ct:1:ci:testnet:dt:bip48:dp:m/48'/1'/0'/2'/1/3:aa:secp256k1:af:p2wsh:ap:tb1q`,
+		},
+		{
+			`urn:mhda:nt:zec:ct:133:ci:main:dt:zip32:dp:m/32h/133h/0h`,
+			`urn:mhda:nt:zec:ct:133:ci:main:dt:zip32:dp:m/32'/133'/0'`,
+			`urn:mhda:sv:2:nt:zec:ct:133:ci:main:dt:zip32:dp:m/32'/133'/0':aa:jubjub:af:sapling:ap:zs`,
+		},
+		{
+			`urn:mhda:nt:zec:ct:1:ci:test:dt:zip32:dp:m/32h/1h/2h/5h:aa:pallas:af:unified:ap:utest`,
+			`urn:mhda:nt:zec:ct:1:ci:test:dt:zip32:dp:m/32'/1'/2'/5':aa:pallas:af:unified:ap:utest`,
+			`urn:mhda:sv:2:nt:zec:ct:1:ci:test:dt:zip32:dp:m/32'/1'/2'/5':aa:pallas:af:unified:ap:utest`,
+		},
+		{
+			`urn:mhda:nt:ada:ct:1815:ci:mainnet:dt:cip1852:dp:m/1852h/1815h/0h/0/0`,
+			`urn:mhda:nt:ada:ct:1815:ci:mainnet:dt:cip1852:dp:m/1852'/1815'/0'/0/0`,
+			`urn:mhda:sv:2:nt:ada:ct:1815:ci:mainnet:dt:cip1852:dp:m/1852'/1815'/0'/0/0:aa:ed25519-bip32:af:bech32:ap:addr1`,
+		},
+		{
+			`urn:mhda:nt:ada:ct:1815:ci:preprod:dt:cip1852:dp:m/1852h/1815h/0h/2/0`,
+			`urn:mhda:nt:ada:ct:1815:ci:preprod:dt:cip1852:dp:m/1852'/1815'/0'/2/0`,
+			`urn:mhda:sv:2:nt:ada:ct:1815:ci:preprod:dt:cip1852:dp:m/1852'/1815'/0'/2/0:aa:ed25519-bip32:af:bech32:ap:stake_test1`,
+		},
+	}
+
+	for _, c := range cases {
+		addr, err := ParseURN(c.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr.String() != c.short {
+			t.Fatal("mismatch short form", addr.String(), c.short)
+		}
+
+		if addr.LongString() != c.long {
+			t.Fatal("mismatch long form", addr.LongString(), c.long)
+		}
+
+		long, err := ParseURNWithOptions(addr.LongString(), StrictParseOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if long.String() != addr.String() || long.Prefix() != addr.Prefix() || long.Suffix() != addr.Suffix() {
+			t.Fatal("long form lost information", long.String(), addr.String())
+		}
+	}
+}
+
+func TestLongFormStrict(t *testing.T) {
+	networkTypes := []NetworkType{`near`}
+
+	for _, networkType := range ntIndex {
+		networkTypes = append(networkTypes, networkType)
+	}
+
+	for _, networkType := range networkTypes {
+		addr, err := ParseURN(`urn:mhda:nt:` + string(networkType) + `:ct:0:ci:` + orDefault(string(defaultChainIds[networkType]), `mainnet`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = ParseURNWithOptions(addr.LongString(), StrictParseOptions); err != nil {
+			t.Fatalf("%s: %s", addr.LongString(), err)
+		}
+	}
+
+	for _, src := range []string{
+		`urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:slip10:dp:m/44h/501h/0h/0h`,
+		`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://polkadot//0`,
+	} {
+		addr, err := ParseURN(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = ParseURNWithOptions(addr.LongString(), StrictParseOptions); err != nil {
+			t.Fatalf("%s: %s", addr.LongString(), err)
+		}
+	}
+}
+
+func TestEquivalentURN(t *testing.T) {
+	base := `urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1`
+
+	equivalent := []string{
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1:aa:secp256k1:af:hex:ap:0x`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/1`,
+		`urn:mhda:nt:evm:ct:60:ci:1:dt:bip44:dp:m/44'/60'/0'/0/1`,
+		`urn:mhda:nt:evm:dt:bip44:dp:m/44H/60H/0H/0/1:ct:60:ci:1:ap:0x`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1?=label=cold`,
+		`urn:mhda:nt:EVM:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1`,
+	}
+
+	different := []string{
+		`urn:mhda:nt:evm:ct:60:ci:0x2:dt:bip44:dp:m/44'/60'/0'/0/1`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1'`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1:ap:0X`,
+	}
+
+	a, err := ParseURN(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, src := range equivalent {
+		ok, err := EquivalentURN(base, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !ok {
+			t.Fatalf("expected equivalent %s", src)
+		}
+
+		b, _ := ParseURN(src)
+		if a.Hash() != b.Hash() {
+			t.Fatalf("expected equal hash for %s", src)
+		}
+	}
+
+	for _, src := range different {
+		ok, err := EquivalentURN(base, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok {
+			t.Fatalf("expected different %s", src)
+		}
+	}
+
+	if !Equal(nil, nil) || Equal(a, nil) {
+		t.Fatal("unexpected nil comparison")
+	}
+}
+
+func TestExtensionComponents(t *testing.T) {
+	err := RegisterComponent(`lb`, func(value string) error {
+		if len(value) > 16 {
+			return errors.New("label is too long")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterComponent(`lb`)
+
+	if err = RegisterComponent(`lb`, nil); !errors.Is(err, ErrComponentRegistered) {
+		t.Fatalf("expected %v, got %v", ErrComponentRegistered, err)
+	}
+
+	for _, key := range []string{`ct`, `ad`, `x-fp`, `l:b`, ``} {
+		if err = RegisterComponent(key, nil); !errors.Is(err, ErrBadComponentKey) {
+			t.Fatalf("expected %v for %q, got %v", ErrBadComponentKey, key, err)
+		}
+	}
+
+	addr, err := ParseURN(`urn:mhda:nt:evm:x-fp:ab12:ct:60:ci:0x1:LB:cold%20wallet:zz:dropped`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if label, ok := addr.(*Address).Extension(`lb`); !ok || label != `cold wallet` {
+		t.Fatalf("unexpected label %q", label)
+	}
+
+	expected := `urn:mhda:nt:evm:ct:60:ci:0x1:lb:cold%20wallet:x-fp:ab12`
+	if addr.String() != expected {
+		t.Fatal("mismatch result", addr.String(), expected)
+	}
+
+	if _, err = ParseURNWithOptions(addr.LongString(), StrictParseOptions); err != nil {
+		t.Fatalf("extensions must be accepted in strict mode, got %v", err)
+	}
+
+	if _, err = ParseURN(`urn:mhda:nt:evm:ct:60:ci:0x1:lb:very-long-label-value`); !errors.Is(err, ErrBadExtension) {
+		t.Fatalf("expected %v, got %v", ErrBadExtension, err)
+	}
+
+	experimental, err := ParseURN(`urn:mhda:nt:evm:ct:60:ci:0x1:x-foo_bar:1:x-Baz:Qux`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if experimental.String() != `urn:mhda:nt:evm:ct:60:ci:0x1:x-Baz:Qux:x-foo_bar:1` {
+		t.Fatalf("experimental keys must be kept as is, got %s", experimental)
+	}
+
+	if _, ok := experimental.(*Address).Extension(`x-baz`); ok {
+		t.Fatal("experimental keys must be case-sensitive")
+	}
+
+	if err = experimental.(*Address).SetExtension(`x-foo_bar`, `2`); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := experimental.(*Address).Extension(`x-foo_bar`); value != `2` {
+		t.Fatalf("unexpected value %q", value)
+	}
+
+	for _, src := range []string{
+		`urn:mhda:nt:evm:ct:60:ci:0x1:foo_bar:1`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:x-:1`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:x-a%2:1`,
+	} {
+		if _, err = ParseURN(src); !errors.Is(err, ErrBadComponentKey) {
+			t.Errorf("%s: expected %v, got %v", src, ErrBadComponentKey, err)
+		}
+	}
+
+	if err = experimental.(*Address).SetExtension(`foo_bar`, `1`); !errors.Is(err, ErrBadComponentKey) {
+		t.Errorf("expected %v, got %v", ErrBadComponentKey, err)
+	}
+
+	if _, err = ParseURNWithOptions(`urn:mhda:nt:evm:ct:60:ci:0x1:x-fp:ab12:lb:cold`, ParseOptions{RequireCanonicalOrder: true}); !errors.Is(err, ErrComponentOrder) {
+		t.Fatalf("expected %v, got %v", ErrComponentOrder, err)
+	}
+}
+
+func TestSpecVersion(t *testing.T) {
+	cases := []struct {
+		src       string
+		version   SpecVersion
+		canonical string
+	}{
+		{
+			`urn:mhda:nt:evm:ct:60:ci:0x1:af:p2pkh`,
+			SpecVersion2,
+			`urn:mhda:nt:evm:ct:60:ci:0x1:af:p2pkh`,
+		},
+		{
+			`urn:mhda:nt:evm:dt:bip44:dp:m/44'/60'/0'/0/1:ct:60:ci:1`,
+			SpecVersion1,
+			`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/0'/0/1`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:ad:p2wpkh`,
+			SpecVersion1,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:af:p2wpkh`,
+		},
+		{
+			`urn:mhda:sv:1:nt:btc:dt:bip44:dp:m/44'/0'/0'/0/0:ct:0:ci:bitcoin:ad:p2wpkh`,
+			SpecVersion1,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip44:dp:m/44'/0'/0'/0/0:af:p2wpkh`,
+		},
+		{
+			`urn:mhda:sv:2:nt:btc:ct:0:ci:bitcoin:ad:p2wpkh`,
+			SpecVersion2,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin`,
+		},
+		{
+			`urn:mhda:sv:3:nt:btc:ct:0:ci:bitcoin`,
+			SpecVersion(3),
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin`,
+		},
+	}
+
+	for _, c := range cases {
+		version, err := DetectVersion(c.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if version != c.version {
+			t.Fatalf("unexpected version %s of %s", version, c.src)
+		}
+
+		canonical, err := Canonicalize(c.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if canonical != c.canonical {
+			t.Fatal("mismatch result", canonical, c.canonical)
+		}
+	}
+
+	if _, err := ParseURNWithOptions(`urn:mhda:sv:1:nt:btc:dt:bip44:dp:m/44'/0'/0'/0/0:ct:0:ci:bitcoin`, ParseOptions{RequireCanonicalOrder: true}); err != nil {
+		t.Fatalf("order of version 1 must be accepted, got %v", err)
+	}
+
+	if _, err := ParseURNWithOptions(`urn:mhda:sv:3:nt:btc:ct:0:ci:bitcoin`, StrictParseOptions); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected %v, got %v", ErrUnsupportedVersion, err)
+	}
+
+	if _, err := ParseURN(`urn:mhda:sv:x:nt:btc:ct:0:ci:bitcoin`); !errors.Is(err, ErrBadSpecVersion) {
+		t.Fatalf("expected %v, got %v", ErrBadSpecVersion, err)
+	}
+}
+
+func TestInferChain(t *testing.T) {
+	opts := ParseOptions{InferChain: true}
+
+	cases := []struct {
+		src       string
+		canonical string
+	}{
+		{
+			`urn:mhda:nt:btc:dt:bip44:dp:m/44h/0h/0h/0/0`,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip44:dp:m/44'/0'/0'/0/0`,
+		},
+		{
+			`urn:mhda:nt:evm:dt:bip44:dp:m/44h/60h/1h/0/5`,
+			`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/1'/0/5`,
+		},
+		{
+			`urn:mhda:nt:cosmos:ci:axelar-dojo-1:dt:cip11:dp:m/44h/118h/0h/0/0`,
+			`urn:mhda:nt:cosmos:ct:118:ci:axelar-dojo-1:dt:cip11:dp:m/44'/118'/0'/0/0`,
+		},
+		{
+			`urn:mhda:nt:btc:ct:0:dt:bip84:dp:m/84h/0h/0h/0/0`,
+			`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip84:dp:m/84'/0'/0'/0/0`,
+		},
+		{
+			`urn:mhda:nt:sol:ct:501:dt:slip10:dp:m/44h`,
+			`urn:mhda:nt:sol:ct:501:ci:mainnet-beta:dt:slip10:dp:m/44'`,
+		},
+	}
+
+	for _, c := range cases {
+		if _, err := ParseURN(c.src); err == nil {
+			t.Fatalf("inference must be disabled by default for %s", c.src)
+		}
+
+		addr, err := ParseURNWithOptions(c.src, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr.String() != c.canonical {
+			t.Fatal("mismatch result", addr.String(), c.canonical)
+		}
+	}
+
+	_, err := ParseURNWithOptions(`urn:mhda:nt:evm:ct:61:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/0`, opts)
+	if !errors.Is(err, ErrCoinTypeMismatch) {
+		t.Fatalf("expected %v, got %v", ErrCoinTypeMismatch, err)
+	}
+}
@@ -0,0 +1,207 @@
+package go_mhda
+
+import "strconv"
+
+// clone returns deep copy of path
+func (dp *DerivationPath) clone() *DerivationPath {
+	result := &DerivationPath{
+		derivationType: dp.derivationType,
+		password:       dp.password,
+	}
+
+	if len(dp.levels) > 0 {
+		result.levels = make([]PathLevel, len(dp.levels))
+		copy(result.levels, dp.levels)
+	}
+
+	if len(dp.junctions) > 0 {
+		result.junctions = make([]Junction, len(dp.junctions))
+		copy(result.junctions, dp.junctions)
+	}
+
+	return result
+}
+
+// withLevel returns copy of path, where level of grammar role is replaced by value
+func (dp *DerivationPath) withLevel(role levelRole, value uint64) (*DerivationPath, error) {
+	if dp == nil {
+		return nil, newParseError(compDerivationPath, 0, ``, ErrBadDerivationPath)
+	}
+
+	grammar := derivationIndex[dp.Type()]
+
+	for i := range grammar {
+		if grammar[i].role != role || i >= len(dp.levels) {
+			continue
+		}
+
+		if value > maxLevelIndex {
+			return nil, newParseError(compDerivationPath, 0, dp.String(), ErrLevelOverflow)
+		}
+
+		if !grammar[i].isValid(uint32(value), dp.levels[i].IsHardened) {
+			return nil, newParseError(compDerivationPath, 0, dp.String(), ErrBadDerivationPath)
+		}
+
+		result := dp.clone()
+		result.levels[i].Index = uint32(value)
+
+		return result, nil
+	}
+
+	return nil, newParseError(compDerivationPath, 0, dp.String(), ErrBadDerivationPath)
+}
+
+// withLast returns copy of path, where the last level or numeric junction is replaced by value,
+// hardened flag is kept
+func (dp *DerivationPath) withLast(value uint64) (*DerivationPath, error) {
+	if dp.Depth() == 0 {
+		return nil, newParseError(compDerivationPath, 0, dp.String(), ErrBadDerivationPath)
+	}
+
+	if value > maxLevelIndex {
+		return nil, newParseError(compDerivationPath, 0, dp.String(), ErrLevelOverflow)
+	}
+
+	result := dp.clone()
+
+	if result.derivationType == SUBSTRATE {
+		last := &result.junctions[len(result.junctions)-1]
+		if !last.IsNumeric() {
+			return nil, newParseError(compDerivationPath, 0, dp.String(), ErrBadDerivationPath)
+		}
+		last.Value = strconv.FormatUint(value, 10)
+		return result, nil
+	}
+
+	depth := len(result.levels) - 1
+
+	rule, ok := derivationIndex[result.derivationType].rule(depth)
+	if !ok || !rule.isValid(uint32(value), result.levels[depth].IsHardened) {
+		return nil, newParseError(compDerivationPath, 0, dp.String(), ErrBadDerivationPath)
+	}
+
+	result.levels[depth].Index = uint32(value)
+
+	return result, nil
+}
+
+// Next returns path of the next sibling node, the last level or numeric junction is incremented,
+// e.g. next address index of address path or next account of account node
+func (dp *DerivationPath) Next() (*DerivationPath, error) {
+	if dp.Depth() == 0 {
+		return nil, newParseError(compDerivationPath, 0, ``, ErrBadDerivationPath)
+	}
+
+	if dp.derivationType == SUBSTRATE {
+		value, err := strconv.ParseUint(dp.junctions[len(dp.junctions)-1].Value, 10, 64)
+		if err != nil {
+			return nil, newParseError(compDerivationPath, 0, dp.String(), ErrBadDerivationPath)
+		}
+		if value >= maxLevelIndex {
+			return nil, newParseError(compDerivationPath, 0, dp.String(), ErrLevelOverflow)
+		}
+		return dp.withLast(value + 1)
+	}
+
+	return dp.withLast(uint64(dp.levels[len(dp.levels)-1].Index) + 1)
+}
+
+// WithIndex returns path with address index, hardened flag of index level is kept.
+// For types without address index level, e.g. SLIP10, CUSTOM or SUBSTRATE, the last level
+// or numeric junction is replaced
+func (dp *DerivationPath) WithIndex(index uint32) (*DerivationPath, error) {
+	if !derivationIndex[dp.Type()].hasLevel(levelIndex) {
+		return dp.withLast(uint64(index))
+	}
+	return dp.withLevel(levelIndex, uint64(index))
+}
+
+// WithAccount returns path with account
+func (dp *DerivationPath) WithAccount(account AccountIndex) (*DerivationPath, error) {
+	return dp.withLevel(levelAccount, uint64(account))
+}
+
+// WithCharge returns path with charge, e.g. sibling address on change chain
+func (dp *DerivationPath) WithCharge(charge ChargeType) (*DerivationPath, error) {
+	return dp.withLevel(levelCharge, uint64(charge))
+}
+
+// Parent returns path without the last level or junction. Derivation type is kept, so parent
+// can be node of grammar, which is shorter than address path, e.g. account node "m/44'/60'/0'"
+// of BIP44, and Child of parent restores address path
+func (dp *DerivationPath) Parent() (*DerivationPath, error) {
+	if dp.Depth() == 0 {
+		return nil, newParseError(compDerivationPath, 0, dp.String(), ErrBadDerivationPath)
+	}
+
+	result := dp.clone()
+
+	if result.derivationType == SUBSTRATE {
+		if len(result.junctions) == 1 {
+			return nil, newParseError(compDerivationPath, 0, dp.String(), ErrBadDerivationPath)
+		}
+		result.junctions = result.junctions[:len(result.junctions)-1]
+		return result, nil
+	}
+
+	result.levels = result.levels[:len(result.levels)-1]
+
+	return result, nil
+}
+
+// Child returns path with appended level, path levels must be prefix of derivation type grammar.
+// Child of SUBSTRATE path is numeric junction
+func (dp *DerivationPath) Child(index uint32, isHardened bool) (*DerivationPath, error) {
+	if dp.Type() == ROOT {
+		return nil, newParseError(compDerivationPath, 0, ``, ErrBadDerivationPath)
+	}
+
+	if index > maxLevelIndex {
+		return nil, newParseError(compDerivationPath, 0, dp.String(), ErrLevelOverflow)
+	}
+
+	result := dp.clone()
+
+	if result.derivationType == SUBSTRATE {
+		result.junctions = append(result.junctions, Junction{
+			Value:  strconv.FormatUint(uint64(index), 10),
+			IsHard: isHardened,
+		})
+		return result, nil
+	}
+
+	result.levels = append(result.levels, PathLevel{Index: index, IsHardened: isHardened})
+
+	if !derivationIndex[result.derivationType].isPrefix(result.levels) {
+		return nil, newParseError(compDerivationPath, 0, formatLevels(result.levels), ErrBadDerivationPath)
+	}
+
+	return result, nil
+}
+
+// IsAncestorOf returns true, when levels or junctions of path are prefix of other path levels,
+// derivation types are not compared
+func (dp *DerivationPath) IsAncestorOf(other *DerivationPath) bool {
+	if dp == nil || other == nil || dp.Depth() >= other.Depth() {
+		return false
+	}
+
+	if (dp.derivationType == SUBSTRATE) != (other.derivationType == SUBSTRATE) {
+		return false
+	}
+
+	for i := range dp.levels {
+		if dp.levels[i] != other.levels[i] {
+			return false
+		}
+	}
+
+	for i := range dp.junctions {
+		if dp.junctions[i] != other.junctions[i] {
+			return false
+		}
+	}
+
+	return true
+}
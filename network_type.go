@@ -1,7 +1,5 @@
 package go_mhda
 
-import "errors"
-
 type NetworkType string
 
 const (
@@ -11,6 +9,9 @@ const (
 	TronVM      = NetworkType(`tvm`)
 	Cosmos      = NetworkType(`cosmos`)
 	Solana      = NetworkType(`sol`)
+	Zcash       = NetworkType(`zec`)
+	Cardano     = NetworkType(`ada`)
+	Polkadot    = NetworkType(`dot`)
 )
 
 var ntIndex = map[string]NetworkType{
@@ -20,6 +21,22 @@ var ntIndex = map[string]NetworkType{
 	`tvm`:    TronVM,
 	`cosmos`: Cosmos,
 	`sol`:    Solana,
+	`zec`:    Zcash,
+	`ada`:    Cardano,
+	`dot`:    Polkadot,
+}
+
+// defaultChainIds are used for "ci" inference, see ParseOptions.InferChain
+var defaultChainIds = map[NetworkType]ChainId{
+	Bitcoin:     `bitcoin`,
+	EthereumVM:  `0x1`,
+	AvalancheVM: `0x1`,
+	TronVM:      `mainnet`,
+	Cosmos:      `cosmoshub-4`,
+	Solana:      `mainnet-beta`,
+	Zcash:       `main`,
+	Cardano:     `mainnet`,
+	Polkadot:    `polkadot`,
 }
 
 func NetworkTypeFromString(src string) (NetworkType, error) {
@@ -27,7 +44,7 @@ func NetworkTypeFromString(src string) (NetworkType, error) {
 	if ok {
 		return result, nil
 	}
-	return result, errors.New("undefined network type")
+	return result, newParseError(compNetworkType, 0, src, ErrUnknownNetworkType)
 }
 
 func (nt NetworkType) IsValid() bool {
@@ -0,0 +1,46 @@
+package go_mhda
+
+// ParseOptions configures strictness of URN and NSS parsing.
+// Zero value is lenient mode, which is used by ParseURN and ParseNSS
+type ParseOptions struct {
+	// RejectUnknown returns error for components, which are not defined by MHDA
+	RejectUnknown bool
+	// RejectDuplicates returns error for repeated components, otherwise first value is used
+	RejectDuplicates bool
+	// RequireCanonicalOrder returns error, when components are not in order
+	// sv, nt, ct, ci, dt, dp, aa, af, ap, as, or in order of SpecVersion1, when it is defined by "sv"
+	RequireCanonicalOrder bool
+	// RequireLongForm returns error, when "aa", "af" or "ap" are not defined explicitly,
+	// components without default value are not required, e.g. "ap" of Solana
+	RequireLongForm bool
+	// CaseSensitiveHardened accepts only "h" and "'" hardened markers in "dp", "H" is rejected
+	CaseSensitiveHardened bool
+	// RejectUnsupportedVersion returns error for "sv" greater than CurrentSpecVersion,
+	// otherwise such URNs are parsed with current grammar
+	RejectUnsupportedVersion bool
+	// InferChain fills missing "ct" from coin level of "dp" and missing "ci" from network default,
+	// explicit "ct" must be equal to coin level of "dp"
+	InferChain bool
+}
+
+var (
+	// LenientParseOptions is default parsing mode
+	LenientParseOptions = ParseOptions{}
+
+	// StrictParseOptions enables all checks, e.g. for URNs from untrusted sources
+	StrictParseOptions = ParseOptions{
+		RejectUnknown:         true,
+		RejectDuplicates:      true,
+		RequireCanonicalOrder: true,
+		RequireLongForm:       true,
+		CaseSensitiveHardened: true,
+
+		RejectUnsupportedVersion: true,
+	}
+
+	longFormComponents = []string{
+		compAddressAlgorithm,
+		compAddressFormat,
+		compAddressPrefix,
+	}
+)
@@ -1,9 +1,8 @@
 package go_mhda
 
 import (
-	"errors"
-	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -15,6 +14,9 @@ const (
 
 	// NSS components
 
+	// compSpecVersion is MHDA grammar version, see SpecVersion
+	compSpecVersion = `sv`
+
 	// Chain domain
 
 	// compNetworkType is Network Type description, e.g. "evm", "tvm", "avm", "btc", "cosmos"
@@ -32,38 +34,57 @@ const (
 
 	// Address format domain
 	compAddressAlgorithm = `aa`
-	compAddressFormat    = `ad`
+	compAddressFormat    = `af`
 	compAddressPrefix    = `ap`
 	compAddressSuffix    = `as`
+
+	// compLegacyAddressFormat is "af" spelling of SpecVersion1
+	compLegacyAddressFormat = `ad`
 )
 
-var (
-	componentsNames = []string{
-		compNetworkType,
-		compDerivationType,
-		compDerivationPath,
-		compCoinType,
-		compChainId,
-		compAddressAlgorithm,
-		compAddressFormat,
-		compAddressPrefix,
-		compAddressSuffix,
-	}
-
-	rxComponent = regexp.MustCompile(`:(nt|ct|ci|dt|dp|aa|af|ap|as):([0-9a-z-._~*+=%$&@?'()!,;/#]+)`)
+// Components positions in canonical order
+const (
+	indexSpecVersion = iota
+	indexNetworkType
+	indexCoinType
+	indexChainId
+	indexDerivationType
+	indexDerivationPath
+	indexAddressAlgorithm
+	indexAddressFormat
+	indexAddressPrefix
+	indexAddressSuffix
+
+	componentsCount
 )
 
-// TODO: Match to RFC 8141
+var (
+	// componentsNames is list of defined components in canonical order
+	componentsNames = [componentsCount]string{
+		indexSpecVersion:      compSpecVersion,
+		indexNetworkType:      compNetworkType,
+		indexCoinType:         compCoinType,
+		indexChainId:          compChainId,
+		indexDerivationType:   compDerivationType,
+		indexDerivationPath:   compDerivationPath,
+		indexAddressAlgorithm: compAddressAlgorithm,
+		indexAddressFormat:    compAddressFormat,
+		indexAddressPrefix:    compAddressPrefix,
+		indexAddressSuffix:    compAddressSuffix,
+	}
+
+	rxComponent = regexp.MustCompile(`:(nt|ct|ci|dt|dp|aa|af|ap|as):([0-9a-z-._~*+=%$&@'()!,;/]+)`)
+)
 
 func ParseURNRx(src string) (MHDA, error) {
 	if !strings.HasPrefix(src, prefixMHDA) {
-		return nil, errors.New("source string is not valid URN MHDA")
+		return nil, newParseError(``, 0, ``, ErrInvalidPrefix)
 	}
 
 	submatches := rxComponent.FindAllStringSubmatch(src[prefixOffset-1:], len(componentsNames))
 
 	if len(submatches) == 0 {
-		return nil, errors.New("no components")
+		return nil, newParseError(``, prefixOffset, ``, ErrMalformedNSS)
 	}
 
 	components := map[string]string{}
@@ -79,81 +100,341 @@ func ParseURNRx(src string) (MHDA, error) {
 
 	return nil, nil
 }
+
+// ParseURN parses URN according RFC 8141, "urn" and "mhda" are case-insensitive.
+// Optional r-, q- and f-components are stored separately from NSS
 func ParseURN(src string) (MHDA, error) {
-	if !strings.HasPrefix(src, prefixMHDA) {
-		return nil, errors.New("source string is not valid URN MHDA")
+	return ParseURNWithOptions(src, LenientParseOptions)
+}
+
+// ParseURNWithOptions parses URN like ParseURN with configured strictness
+func ParseURNWithOptions(src string, opts ParseOptions) (MHDA, error) {
+	address := &Address{}
+
+	err := parseURNInto(src, address, &opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// ParseURNInto parses URN directly into address in a single pass, without heap allocations
+// for URNs without percent-encoded values. Chain and DerivationPath, referenced by address,
+// are overwritten in place, so they must not be shared with another addresses.
+// On failure address can be partially filled
+func ParseURNInto(src string, address *Address) error {
+	return parseURNInto(src, address, &LenientParseOptions)
+}
+
+func parseURNInto(src string, address *Address, opts *ParseOptions) error {
+	if len(src) < prefixOffset || !strings.EqualFold(src[:prefixOffset], prefixMHDA) {
+		return newParseError(``, 0, ``, ErrInvalidPrefix)
+	}
+
+	nss, rqf, err := splitRQF(src[prefixOffset:])
+
+	if err != nil {
+		return withOffset(err, prefixOffset)
+	}
+
+	err = parseNSSInto(nss, address, opts)
+
+	if err != nil {
+		return withOffset(err, prefixOffset)
 	}
 
-	return ParseNSS(src[prefixOffset:])
+	address.rqf = rqf
+
+	return nil
 }
 
 func ParseNSS(src string) (MHDA, error) {
-	var componentsNamesTmp = make([]string, len(componentsNames))
+	return ParseNSSWithOptions(src, LenientParseOptions)
+}
 
-	copy(componentsNamesTmp, componentsNames)
+// ParseNSSWithOptions parses NSS like ParseNSS with configured strictness
+func ParseNSSWithOptions(src string, opts ParseOptions) (MHDA, error) {
+	address := &Address{}
 
-	components, err := parseNSS(src, componentsNamesTmp)
+	err := parseNSSInto(src, address, &opts)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if _, ok := components[compNetworkType]; !ok {
-		return nil, errors.New(`"nt" not defined`)
+	return address, nil
+}
+
+// ParseNSSInto parses NSS directly into address, see ParseURNInto
+func ParseNSSInto(src string, address *Address) error {
+	return parseNSSInto(src, address, &LenientParseOptions)
+}
+
+func parseNSSInto(src string, address *Address, opts *ParseOptions) error {
+	var components nssComponents
+
+	err := parseNSS(src, &components, opts)
+
+	if err != nil {
+		return err
+	}
+
+	if !components.values[indexNetworkType].isSet {
+		return newParseError(compNetworkType, 0, ``, ErrMissingNetworkType)
+	}
+
+	if opts.InferChain {
+		err = components.inferChain()
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.CaseSensitiveHardened && !strings.EqualFold(components.values[indexDerivationType].value, string(SUBSTRATE)) {
+		dp := components.values[indexDerivationPath]
+		if pos := strings.IndexByte(dp.value, 'H'); pos >= 0 {
+			return newParseError(compDerivationPath, dp.offset+pos, dp.value, ErrBadDerivationPath)
+		}
+	}
+
+	err = address.setComponents(&components)
+
+	if err != nil {
+		return err
+	}
+
+	if opts.RequireLongForm {
+		return components.checkLongForm(address, len(src))
+	}
+
+	return nil
+}
+
+// checkLongForm checks, that components of long form are defined. Components without default
+// value, e.g. "ap" of Solana or all of unknown network types, are not required, see LongString
+func (c *nssComponents) checkLongForm(address *Address, offset int) error {
+	defaults := defaultsFor(address.chain, address.path)
+
+	for _, key := range longFormComponents {
+		if c.values[componentIndex(key)].isSet {
+			continue
+		}
+
+		if (key == compAddressAlgorithm && defaults.algorithm == ``) ||
+			(key == compAddressFormat && defaults.format == ``) ||
+			(key == compAddressPrefix && defaults.prefix == ``) {
+			continue
+		}
+
+		return newParseError(key, offset, ``, ErrMissingComponent)
+	}
+
+	return nil
+}
+
+type nssComponent struct {
+	value  string
+	offset int
+	isSet  bool
+}
+
+// nssLegacy holds components of older grammar versions
+type nssLegacy struct {
+	addressFormat nssComponent
+	isOrder       bool
+}
+
+type nssExtension struct {
+	key    string
+	value  string
+	offset int
+}
+
+// nssComponents holds parsed values by components positions and extension components
+type nssComponents struct {
+	values     [componentsCount]nssComponent
+	extensions []nssExtension
+	legacy     nssLegacy
+	version    SpecVersion
+}
+
+// inferChain fills missing chain components from derivation path and network defaults
+func (c *nssComponents) inferChain() error {
+	dt := DerivationType(strings.ToLower(strings.TrimSpace(c.values[indexDerivationType].value)))
+	dp := c.values[indexDerivationPath]
+
+	if dp.isSet && derivationIndex[dt].hasLevel(levelCoin) {
+		path := DerivationPath{derivationType: dt}
+
+		err := path.ParsePath(strings.ToLower(strings.TrimSpace(dp.value)))
+		if err != nil {
+			return withOffset(err, dp.offset)
+		}
+
+		// coin level is optional for some derivation types, e.g. SLIP10
+		if coin, ok := path.level(levelCoin); ok {
+			ct := c.values[indexCoinType]
+
+			if !ct.isSet {
+				c.values[indexCoinType] = nssComponent{
+					value:  strconv.FormatUint(uint64(coin.Index), 10),
+					offset: dp.offset,
+					isSet:  true,
+				}
+			} else if coinType, err := strconv.ParseUint(strings.TrimSpace(ct.value), 0, 32); err == nil && CoinType(coinType) != CoinType(coin.Index) {
+				return newParseError(compCoinType, ct.offset, ct.value, ErrCoinTypeMismatch)
+			}
+		}
+	}
+
+	if !c.values[indexChainId].isSet {
+		chainId, ok := defaultChainIds[NetworkType(strings.ToLower(strings.TrimSpace(c.values[indexNetworkType].value)))]
+		if ok {
+			c.values[indexChainId] = nssComponent{
+				value:  string(chainId),
+				offset: c.values[indexNetworkType].offset,
+				isSet:  true,
+			}
+		}
 	}
 
-	return parseAddress(components)
+	return nil
+}
+
+func (c *nssComponents) extensionIndex(key string) int {
+	for i := range c.extensions {
+		if c.extensions[i].key == key {
+			return i
+		}
+	}
+	return -1
 }
 
-func parseNSS(nss string, components []string) (map[string]string, error) {
-	result := map[string]string{}
+// componentIndex returns position of component in canonical order, or -1 for unknown component.
+// Keys are case-insensitive
+func componentIndex(key string) int {
+	for i := range componentsNames {
+		if strings.EqualFold(componentsNames[i], key) {
+			return i
+		}
+	}
+	return -1
+}
 
+// parseNSS splits nss to components, values are percent-decoded.
+// In lenient mode unknown and repeated components are skipped, registered and
+// "x-" prefixed extension components are collected
+func parseNSS(nss string, result *nssComponents, opts *ParseOptions) error {
 	iter := 0
+	lastRank := -1
 
 	for iter < len(nss) {
-		var isFound bool
-		for i := range components {
-			if nss[iter] == components[i][0] && nss[iter+1] == components[i][1] {
-				componentIndex := nss[iter : iter+2]
-				componentValue := ``
-				iterVal := iter + 3
-				for iterVal < len(nss) {
-					if nss[iterVal] == 58 { // 58 [:]  separator
-						break
-					}
-
-					// ASCII checks instead regexp for performance
-
-					if (nss[iterVal] >= 48 && nss[iterVal] <= 57) || // 48-57  [0-9]
-						(nss[iterVal] >= 97 && nss[iterVal] <= 122) || // 97-122 [a-z]
-						(nss[iterVal] >= 35 && nss[iterVal] <= 47) || // 35-47 [!#$%&'()*+,-./]
-						(nss[iterVal] >= 65 && nss[iterVal] <= 90) || // 65-90 [A-Z]
-						nss[iterVal] == 95 || // 95 [_]
-						nss[iterVal] == 33 || // 33 [!]
-						nss[iterVal] == 59 || // 59 [;]
-						nss[iterVal] == 61 || // 61 [=]
-						nss[iterVal] == 63 || // 63 [?]
-						nss[iterVal] == 64 { // 64 [@]
-
-						isFound = true
-						componentValue += nss[iterVal : iterVal+1]
-						iterVal++
-					} else {
-						return nil, fmt.Errorf("cannot parse nss: wrong symbol %q, pos %d", nss[iterVal], iterVal)
-					}
+		keyLen := strings.IndexByte(nss[iter:], ':')
+		if keyLen < 0 {
+			return newParseError(nss[iter:], iter, ``, ErrMalformedNSS)
+		}
+
+		key := nss[iter : iter+keyLen]
+		iterVal := iter + keyLen + 1
+
+		valueLen := strings.IndexByte(nss[iterVal:], ':') // 58 [:]  separator
+		if valueLen < 0 {
+			valueLen = len(nss) - iterVal
+		}
+
+		componentValue := nss[iterVal : iterVal+valueLen]
+
+		if componentValue == `` {
+			return newParseError(key, iterVal, ``, ErrMalformedNSS)
+		}
+
+		if pos := invalidComponentIndex(componentValue); pos >= 0 {
+			return newParseError(key, iterVal+pos, nss[iterVal+pos:iterVal+pos+1], ErrMalformedNSS)
+		}
+
+		index := componentIndex(key)
+
+		if index < 0 && strings.EqualFold(key, compLegacyAddressFormat) {
+			if !result.legacy.addressFormat.isSet {
+				result.legacy.addressFormat = nssComponent{
+					value:  unescapeComponent(componentValue),
+					offset: iterVal,
+					isSet:  true,
+				}
+			}
+		} else if index < 0 {
+			extKey, err := extensionKey(key)
+
+			if err != nil {
+				// malformed keys are rejected in lenient mode too
+				if opts.RejectUnknown || err == ErrBadComponentKey {
+					return newParseError(key, iter, componentValue, err)
+				}
+			} else if result.extensionIndex(extKey) >= 0 {
+				if opts.RejectDuplicates {
+					return newParseError(key, iter, componentValue, ErrDuplicateComponent)
+				}
+			} else {
+				// extensions follow defined components, sorted by key
+				if opts.RequireCanonicalOrder && len(result.extensions) > 0 &&
+					extKey < result.extensions[len(result.extensions)-1].key {
+					return newParseError(key, iter, componentValue, ErrComponentOrder)
+				}
+				lastRank = componentsCount
+
+				result.extensions = append(result.extensions, nssExtension{
+					key:    extKey,
+					value:  unescapeComponent(componentValue),
+					offset: iterVal,
+				})
+			}
+		} else if result.values[index].isSet {
+			if opts.RejectDuplicates {
+				return newParseError(key, iter, componentValue, ErrDuplicateComponent)
+			}
+		} else {
+			rank := componentRank(result.version, index)
+			if opts.RequireCanonicalOrder && rank < lastRank {
+				return newParseError(key, iter, componentValue, ErrComponentOrder)
+			}
+			lastRank = rank
+
+			if (index == indexCoinType || index == indexChainId) && result.values[indexDerivationType].isSet {
+				result.legacy.isOrder = true
+			}
+
+			result.values[index] = nssComponent{
+				value:  unescapeComponent(componentValue),
+				offset: iterVal,
+				isSet:  true,
+			}
 
+			if index == indexSpecVersion {
+				version, err := parseSpecVersion(componentValue)
+				if err != nil {
+					return withOffset(err, iterVal)
 				}
-				result[componentIndex] = componentValue
-				if isFound {
-					components = append(components[:i], components[i+1:]...)
-					iter = iterVal + 1
-					break
+				if opts.RejectUnsupportedVersion && version > CurrentSpecVersion {
+					return newParseError(key, iterVal, componentValue, ErrUnsupportedVersion)
 				}
+				result.version = version
 			}
 		}
-		if !isFound {
-			iter += 3
+
+		iter = iterVal + valueLen + 1
+
+		if iter == len(nss) {
+			return newParseError(``, iter-1, ``, ErrMalformedNSS)
 		}
 	}
-	return result, nil
+
+	err := result.upgradeLegacy(opts)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
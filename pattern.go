@@ -0,0 +1,280 @@
+package go_mhda
+
+import (
+	"strconv"
+	"strings"
+)
+
+const wildcard = `*`
+
+// levelPattern matches single derivation path level
+type levelPattern struct {
+	isAny      bool
+	intervals  [][2]uint32
+	isHardened bool
+}
+
+// Pattern matches addresses by components values, compiled from URN or NSS with wildcards, e.g.
+// "nt:evm:ct:60:ci:*:dt:bip44:dp:m/44h/60h/*h/0/*". Components, which are not defined
+// in pattern, match any value
+type Pattern struct {
+	src        string
+	components map[string]string
+	path       []levelPattern
+	isAnyPath  bool
+}
+
+// CompilePattern compiles pattern. Component value can contain "*" wildcards, which match any
+// symbols. Every "dp" level can be "*", number, range "a-b" or list "{a,b-c}", followed by
+// hardened marker for hardened levels, e.g. "*h" matches any hardened level
+func CompilePattern(src string) (*Pattern, error) {
+	p := &Pattern{
+		src:        src,
+		components: map[string]string{},
+	}
+
+	nss := src
+	offset := 0
+
+	if len(src) >= prefixOffset && strings.EqualFold(src[:prefixOffset], prefixMHDA) {
+		nss = src[prefixOffset:]
+		offset = prefixOffset
+	}
+
+	tokens := strings.Split(nss, `:`)
+
+	if len(tokens)%2 != 0 {
+		return nil, newParseError(``, offset+len(nss), ``, ErrMalformedNSS)
+	}
+
+	for i := 0; i < len(tokens); i += 2 {
+		key := tokens[i]
+		value := tokens[i+1]
+		valueOffset := offset + len(tokens[i]) + 1
+
+		if value == `` {
+			return nil, newParseError(key, valueOffset, ``, ErrMalformedNSS)
+		}
+
+		// braces of levels lists are validated by compilePath
+		if !isExperimentalKey(key) {
+			key = strings.ToLower(key)
+		}
+
+		if pos := invalidComponentIndex(value); pos >= 0 && key != compDerivationPath {
+			return nil, newParseError(key, valueOffset+pos, value[pos:pos+1], ErrMalformedNSS)
+		}
+
+		if componentIndex(key) < 0 {
+			extKey, err := extensionKey(key)
+			if err != nil {
+				return nil, newParseError(key, offset, value, err)
+			}
+			key = extKey
+		}
+
+		if _, ok := p.components[key]; ok {
+			return nil, newParseError(key, offset, value, ErrDuplicateComponent)
+		}
+
+		if key == compDerivationPath {
+			if err := p.compilePath(value); err != nil {
+				return nil, withOffset(err, valueOffset)
+			}
+		}
+
+		p.components[key] = unescapeComponent(value)
+
+		offset = valueOffset + len(value) + 1
+	}
+
+	return p, nil
+}
+
+// MustCompilePattern is like CompilePattern, but panics on error
+func MustCompilePattern(src string) *Pattern {
+	p, err := CompilePattern(src)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+func (p *Pattern) compilePath(dp string) error {
+	if dp == wildcard {
+		p.isAnyPath = true
+		return nil
+	}
+
+	levels := strings.Split(dp, `/`)
+
+	if levels[0] != `m` {
+		return newParseError(compDerivationPath, 0, dp, ErrBadDerivationPath)
+	}
+
+	for _, level := range levels[1:] {
+		var pattern levelPattern
+
+		if len(level) > 0 && isHardenedMarker(level[len(level)-1]) {
+			pattern.isHardened = true
+			level = level[:len(level)-1]
+		}
+
+		if level == wildcard {
+			pattern.isAny = true
+		} else {
+			if strings.HasPrefix(level, `{`) && strings.HasSuffix(level, `}`) {
+				level = level[1 : len(level)-1]
+			}
+
+			for _, item := range strings.Split(level, `,`) {
+				interval, err := parseInterval(item)
+				if err != nil {
+					return newParseError(compDerivationPath, 0, dp, ErrBadRange)
+				}
+				pattern.intervals = append(pattern.intervals, interval)
+			}
+		}
+
+		p.path = append(p.path, pattern)
+	}
+
+	return nil
+}
+
+func (l *levelPattern) match(level PathLevel) bool {
+	if l.isHardened != level.IsHardened {
+		return false
+	}
+
+	if l.isAny {
+		return true
+	}
+
+	for _, interval := range l.intervals {
+		if level.Index >= interval[0] && level.Index <= interval[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob matches value with pattern, where "*" matches any symbols
+func matchGlob(pattern, value string) bool {
+	star := strings.IndexByte(pattern, '*')
+
+	if star < 0 {
+		return pattern == value
+	}
+
+	if !strings.HasPrefix(value, pattern[:star]) {
+		return false
+	}
+
+	value = value[star:]
+	pattern = pattern[star+1:]
+
+	for i := 0; i <= len(value); i++ {
+		if matchGlob(pattern, value[i:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match returns true, when all components of pattern match address
+func (p *Pattern) Match(address MHDA) bool {
+	if address == nil {
+		return false
+	}
+
+	chain := address.Chain()
+	path := address.DerivationPath()
+	defaults := defaultsFor(chain, path)
+
+	for key, pattern := range p.components {
+		var value string
+
+		switch key {
+		case compSpecVersion:
+			continue
+		case compNetworkType:
+			value = string(chain.networkType)
+			pattern = strings.ToLower(pattern)
+		case compCoinType:
+			value = strconv.FormatUint(uint64(chain.coinType), 10)
+			if coinType, err := strconv.ParseUint(pattern, 0, 32); err == nil {
+				pattern = strconv.FormatUint(coinType, 10)
+			}
+		case compChainId:
+			value = chain.canonicalChainId()
+			if !strings.Contains(pattern, wildcard) {
+				pattern = (&Chain{networkType: chain.networkType, chainId: ChainId(pattern)}).canonicalChainId()
+			}
+		case compDerivationType:
+			value = string(path.Type())
+			pattern = strings.ToLower(pattern)
+		case compDerivationPath:
+			if !p.matchPath(path) {
+				return false
+			}
+			continue
+		case compAddressAlgorithm:
+			value = orDefault(string(address.Algorithm()), string(defaults.algorithm))
+			pattern = strings.ToLower(pattern)
+		case compAddressFormat:
+			value = orDefault(string(address.Format()), string(defaults.format))
+			pattern = strings.ToLower(pattern)
+		case compAddressPrefix:
+			value = orDefault(address.Prefix(), defaults.prefix)
+		case compAddressSuffix:
+			value = address.Suffix()
+		default:
+			var ok bool
+			if value, ok = address.Extension(key); !ok {
+				return false
+			}
+		}
+
+		if !matchGlob(pattern, value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Pattern) matchPath(path *DerivationPath) bool {
+	if p.isAnyPath {
+		return true
+	}
+
+	levels := path.Levels()
+
+	if len(levels) != len(p.path) {
+		return false
+	}
+
+	for i := range levels {
+		if !p.path[i].match(levels[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func orDefault(value, defaultValue string) string {
+	if value == `` {
+		return defaultValue
+	}
+	return value
+}
+
+func (p *Pattern) String() string {
+	return p.src
+}
@@ -0,0 +1,76 @@
+package go_mhda
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPattern(t *testing.T) {
+	var addresses []MHDA
+
+	for _, src := range []string{
+		`urn:mhda:nt:evm:ct:60:ci:0x38:dt:bip44:dp:m/44h/60h/3h/0/17`,
+		`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip84:dp:m/84h/0h/0h/1/5`,
+		`urn:mhda:nt:cosmos:ct:118:ci:cosmoshub-4:dt:cip11:dp:m/44h/118h/0h/0/0:aa:secp256k1:x-label:cold`,
+	} {
+		address, err := ParseURN(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	var tests = []struct {
+		pattern string
+		matches [3]bool
+	}{
+		{`nt:*`, [3]bool{true, true, true}},
+		{`urn:mhda:nt:evm`, [3]bool{true, false, false}},
+		{`nt:evm:ct:60:ci:*:dt:bip44:dp:m/44h/60h/*h/0/*`, [3]bool{true, false, false}},
+		{`nt:evm:ci:56`, [3]bool{true, false, false}},
+		{`nt:evm:ct:0x3c`, [3]bool{true, false, false}},
+		{`dp:m/44h/60h/0-4h/0/{0-9,17}`, [3]bool{true, false, false}},
+		{`dp:m/44h/60h/0-2h/0/*`, [3]bool{false, false, false}},
+		{`dp:m/44h/60h/*/0/*`, [3]bool{false, false, false}},
+		{`dp:m/*h/*h/*h/*/*`, [3]bool{true, true, true}},
+		{`dp:m/*h/*h/*h/*`, [3]bool{false, false, false}},
+		{`dt:bip84:dp:*`, [3]bool{false, true, false}},
+		{`af:p2wpkh`, [3]bool{false, true, false}},
+		{`ap:bc1*`, [3]bool{false, true, false}},
+		{`ci:cosmos*`, [3]bool{false, false, true}},
+		{`aa:secp256k1`, [3]bool{true, true, true}},
+		{`x-label:c*d`, [3]bool{false, false, true}},
+	}
+
+	for _, tt := range tests {
+		p, err := CompilePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.pattern, err)
+		}
+
+		for i, address := range addresses {
+			if p.Match(address) != tt.matches[i] {
+				t.Errorf("%s: unexpected match %v of %s", tt.pattern, !tt.matches[i], address)
+			}
+		}
+	}
+
+	if MustCompilePattern(`nt:*`).Match(nil) {
+		t.Error("nil address matched")
+	}
+
+	for src, target := range map[string]error{
+		`nt:evm:ct`:           ErrMalformedNSS,
+		`nt:evm:ct:`:          ErrMalformedNSS,
+		`nt:evm:zz:1`:         ErrUnknownComponent,
+		`nt:evm:nt:btc`:       ErrDuplicateComponent,
+		`dp:44h/60h`:          ErrBadDerivationPath,
+		`dp:m/44h/5-1`:        ErrBadRange,
+		`dp:m/44h/{0,a}`:      ErrBadRange,
+		`dp:m/44h/2147483648`: ErrBadRange,
+	} {
+		if _, err := CompilePattern(src); !errors.Is(err, target) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package go_mhda
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// levelRange is list of closed intervals of single derivation path level
+type levelRange struct {
+	placeholder string
+	intervals   [][2]uint32
+	count       uint64
+}
+
+// RangeIterator lazily generates addresses for all combinations of "dp" levels ranges,
+// the last level is changed first
+type RangeIterator struct {
+	template *Template
+	levels   []levelRange
+	count    uint64
+	position uint64
+	current  *Address
+	err      error
+}
+
+// ParseRange parses URN or NSS, where "dp" levels can be defined as ranges, e.g.
+// "m/44h/60h/0h/0/0-999" or "m/84h/0h/{0-4}h/{0,1}/0-19". Level range is "a-b",
+// or list of values and ranges in braces "{a,b-c}", hardened marker follows range.
+// Values of level are generated once in ascending order, overlapping ranges are merged
+func ParseRange(src string) (*RangeIterator, error) {
+	var (
+		nss    = src
+		offset = 0
+		r      = &RangeIterator{count: 1}
+	)
+
+	if len(src) >= prefixOffset && strings.EqualFold(src[:prefixOffset], prefixMHDA) {
+		nss = src[prefixOffset:]
+		offset = prefixOffset
+	}
+
+	if idx := strings.IndexAny(nss, `?#`); idx >= 0 {
+		nss = nss[:idx]
+	}
+
+	tokens := strings.Split(nss, `:`)
+	templateSrc := src
+
+	for i := 1; i < len(tokens); i += 2 {
+		offset += len(tokens[i-1]) + 1
+
+		if strings.EqualFold(tokens[i-1], compDerivationPath) {
+			dp, err := r.parseLevels(tokens[i], offset)
+			if err != nil {
+				return nil, err
+			}
+			templateSrc = src[:offset] + dp + src[offset+len(tokens[i]):]
+			break
+		}
+
+		offset += len(tokens[i]) + 1
+	}
+
+	template, err := parseTemplate(templateSrc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.template = template
+
+	// validates grammar with minimal and maximal values of all levels
+	minValues := make(map[string]uint32, len(r.levels))
+	maxValues := make(map[string]uint32, len(r.levels))
+
+	for i := range r.levels {
+		minValues[r.levels[i].placeholder], maxValues[r.levels[i].placeholder] = r.levels[i].bounds()
+	}
+
+	if _, err = template.Expand(minValues); err != nil {
+		return nil, err
+	}
+
+	if _, err = template.Expand(maxValues); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// parseLevels replaces ranged levels of derivation path with template placeholders
+func (r *RangeIterator) parseLevels(dp string, offset int) (string, error) {
+	levels := strings.Split(dp, `/`)
+
+	for i := 1; i < len(levels); i++ {
+		body := levels[i]
+		marker := ``
+
+		if len(body) > 0 && isHardenedMarker(body[len(body)-1]) {
+			marker = body[len(body)-1:]
+			body = body[:len(body)-1]
+		}
+
+		if strings.HasPrefix(body, `{`) {
+			if !strings.HasSuffix(body, `}`) {
+				return ``, newParseError(compDerivationPath, offset, dp, ErrBadRange)
+			}
+			body = body[1 : len(body)-1]
+		} else if strings.IndexByte(body, '-') < 0 {
+			continue
+		}
+
+		level := levelRange{placeholder: `level` + strconv.Itoa(i)}
+
+		for _, item := range strings.Split(body, `,`) {
+			interval, err := parseInterval(item)
+			if err != nil {
+				return ``, newParseError(compDerivationPath, offset, dp, ErrBadRange)
+			}
+			level.intervals = append(level.intervals, interval)
+		}
+
+		level.intervals = mergeIntervals(level.intervals)
+
+		for _, interval := range level.intervals {
+			level.count += uint64(interval[1]-interval[0]) + 1
+		}
+
+		if r.count > math.MaxUint64/level.count {
+			return ``, newParseError(compDerivationPath, offset, dp, ErrBadRange)
+		}
+
+		r.count *= level.count
+		r.levels = append(r.levels, level)
+
+		levels[i] = `{` + level.placeholder + `}` + marker
+	}
+
+	return strings.Join(levels, `/`), nil
+}
+
+// parseInterval parses "a" or "a-b" interval
+func parseInterval(src string) ([2]uint32, error) {
+	var interval [2]uint32
+
+	bounds := strings.SplitN(src, `-`, 2)
+
+	for i := range bounds {
+		value, err := strconv.ParseUint(bounds[i], 10, 32)
+		if err != nil || value > maxLevelIndex {
+			return interval, ErrBadRange
+		}
+		interval[i] = uint32(value)
+	}
+
+	if len(bounds) == 1 {
+		interval[1] = interval[0]
+	}
+
+	if interval[0] > interval[1] {
+		return interval, ErrBadRange
+	}
+
+	return interval, nil
+}
+
+// mergeIntervals sorts intervals and merges overlapping and adjacent ones,
+// so every level value is generated once
+func mergeIntervals(intervals [][2]uint32) [][2]uint32 {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i][0] < intervals[j][0]
+	})
+
+	result := intervals[:1]
+
+	for _, interval := range intervals[1:] {
+		last := &result[len(result)-1]
+
+		if uint64(interval[0]) <= uint64(last[1])+1 {
+			if interval[1] > last[1] {
+				last[1] = interval[1]
+			}
+			continue
+		}
+
+		result = append(result, interval)
+	}
+
+	return result
+}
+
+func (l *levelRange) bounds() (uint32, uint32) {
+	var lower, upper = l.intervals[0][0], l.intervals[0][1]
+
+	for _, interval := range l.intervals[1:] {
+		if interval[0] < lower {
+			lower = interval[0]
+		}
+		if interval[1] > upper {
+			upper = interval[1]
+		}
+	}
+
+	return lower, upper
+}
+
+func (l *levelRange) value(n uint64) uint32 {
+	for _, interval := range l.intervals {
+		size := uint64(interval[1]-interval[0]) + 1
+		if n < size {
+			return interval[0] + uint32(n)
+		}
+		n -= size
+	}
+	return 0
+}
+
+func (r *RangeIterator) expand(position uint64) (*Address, error) {
+	values := make(map[string]uint32, len(r.levels))
+
+	for i := len(r.levels) - 1; i >= 0; i-- {
+		values[r.levels[i].placeholder] = r.levels[i].value(position % r.levels[i].count)
+		position /= r.levels[i].count
+	}
+
+	return r.template.Expand(values)
+}
+
+// Count returns total number of addresses
+func (r *RangeIterator) Count() uint64 {
+	return r.count
+}
+
+// Next generates next address, returns false when all addresses are generated or on error
+func (r *RangeIterator) Next() bool {
+	if r.err != nil || r.position >= r.count {
+		r.current = nil
+		return false
+	}
+
+	r.current, r.err = r.expand(r.position)
+
+	if r.err != nil {
+		r.current = nil
+		return false
+	}
+
+	r.position++
+
+	return true
+}
+
+// Address returns address, generated by the last Next call
+func (r *RangeIterator) Address() *Address {
+	return r.current
+}
+
+// Err returns error, which stopped iteration
+func (r *RangeIterator) Err() error {
+	return r.err
+}
+
+// Reset restarts iteration from the first address
+func (r *RangeIterator) Reset() {
+	r.position = 0
+	r.current = nil
+	r.err = nil
+}
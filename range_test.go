@@ -0,0 +1,99 @@
+package go_mhda
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	r, err := ParseRange(`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip84:dp:m/84h/0h/{0-2,4}h/{0,1}/0-19`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Count() != 4*2*20 {
+		t.Fatalf("unexpected count %d", r.Count())
+	}
+
+	var n uint64
+
+	for r.Next() {
+		path := r.Address().DerivationPath()
+
+		index := uint32(n % 20)
+		charge := ChargeType(n / 20 % 2)
+		account := AccountIndex(n / 40)
+		if account == 3 {
+			account = 4
+		}
+
+		if path.AddressIndex().Index != index || path.Charge() != charge || path.Account() != account {
+			t.Fatalf("unexpected address %d: %s", n, r.Address())
+		}
+
+		n++
+	}
+
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+
+	if n != r.Count() {
+		t.Fatalf("unexpected generated count %d", n)
+	}
+
+	r.Reset()
+
+	if !r.Next() || r.Address().String() != `urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip84:dp:m/84'/0'/0'/0/0` {
+		t.Fatal("unexpected first address after reset")
+	}
+
+	single, err := ParseRange(`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/5`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if single.Count() != 1 {
+		t.Fatalf("unexpected count %d", single.Count())
+	}
+
+	for src, expected := range map[string][]uint32{
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/{0-1,1}`:     {0, 1},
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/{3,3,3}`:     {3},
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/{5-7,0,2-6}`: {0, 2, 3, 4, 5, 6, 7},
+	} {
+		overlap, err := ParseRange(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if overlap.Count() != uint64(len(expected)) {
+			t.Fatalf("%s: unexpected count %d", src, overlap.Count())
+		}
+
+		var indexes []uint32
+
+		for overlap.Next() {
+			indexes = append(indexes, overlap.Address().DerivationPath().AddressIndex().Index)
+		}
+
+		if !reflect.DeepEqual(indexes, expected) {
+			t.Fatalf("%s: unexpected indexes %v", src, indexes)
+		}
+	}
+
+	for _, src := range []string{
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/9-1`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/{0,1`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/0-2147483648`,
+	} {
+		if _, err = ParseRange(src); !errors.Is(err, ErrBadRange) {
+			t.Fatalf("expected %v for %s, got %v", ErrBadRange, src, err)
+		}
+	}
+
+	if _, err = ParseRange(`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/{0-2}/0`); !errors.Is(err, ErrBadDerivationPath) {
+		t.Fatalf("expected %v, got %v", ErrBadDerivationPath, err)
+	}
+}
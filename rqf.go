@@ -0,0 +1,102 @@
+package go_mhda
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	prefixResolution = `?+`
+	prefixQuery      = `?=`
+	prefixFragment   = `#`
+)
+
+// RQFComponents - optional RFC 8141 components, attached to the assigned name.
+// They are not a part of NSS, so they are not used for NSS() and NSSHash()
+type RQFComponents struct {
+	// Resolution is r-component, passed after "?+"
+	Resolution string
+	// Query is q-component, passed after "?=", e.g. "label=cold&confirmations=6"
+	Query string
+	// Fragment is f-component, passed after "#"
+	Fragment string
+}
+
+// IsEmpty returns true, when no one component is defined
+func (c RQFComponents) IsEmpty() bool {
+	return c.Resolution == `` && c.Query == `` && c.Fragment == ``
+}
+
+// validate checks, that components are split unambiguously by splitRQF:
+// r-component can't contain "?=" and "#", q-component can't contain "#"
+func (c RQFComponents) validate() error {
+	if pos := strings.Index(c.Resolution, prefixQuery); pos >= 0 {
+		return newParseError(``, pos, c.Resolution, ErrBadRQFComponent)
+	}
+
+	if pos := strings.Index(c.Resolution, prefixFragment); pos >= 0 {
+		return newParseError(``, pos, c.Resolution, ErrBadRQFComponent)
+	}
+
+	if pos := strings.Index(c.Query, prefixFragment); pos >= 0 {
+		return newParseError(``, pos, c.Query, ErrBadRQFComponent)
+	}
+
+	return nil
+}
+
+// QueryValues parses q-component as "key=value" pairs, separated by "&"
+func (c RQFComponents) QueryValues() (url.Values, error) {
+	return url.ParseQuery(c.Query)
+}
+
+func (c RQFComponents) String() string {
+	var result string
+
+	if c.Resolution != `` {
+		result += prefixResolution + c.Resolution
+	}
+
+	if c.Query != `` {
+		result += prefixQuery + c.Query
+	}
+
+	if c.Fragment != `` {
+		result += prefixFragment + c.Fragment
+	}
+
+	return result
+}
+
+// splitRQF separates assigned name from r-, q- and f-components
+// assigned-name [ "?+" r-component ] [ "?=" q-component ] [ "#" f-component ]
+func splitRQF(src string) (string, RQFComponents, error) {
+	var rqf RQFComponents
+
+	if idx := strings.Index(src, prefixFragment); idx >= 0 {
+		rqf.Fragment = src[idx+len(prefixFragment):]
+		src = src[:idx]
+	}
+
+	if idx := strings.Index(src, prefixQuery); idx >= 0 {
+		rqf.Query = src[idx+len(prefixQuery):]
+		if rqf.Query == `` {
+			return ``, rqf, newParseError(``, idx, prefixQuery, ErrBadRQFComponent)
+		}
+		src = src[:idx]
+	}
+
+	if idx := strings.Index(src, prefixResolution); idx >= 0 {
+		rqf.Resolution = src[idx+len(prefixResolution):]
+		if rqf.Resolution == `` {
+			return ``, rqf, newParseError(``, idx, prefixResolution, ErrBadRQFComponent)
+		}
+		src = src[:idx]
+	}
+
+	if idx := strings.IndexByte(src, '?'); idx >= 0 {
+		return ``, rqf, newParseError(``, idx, `?`, ErrMalformedNSS)
+	}
+
+	return src, rqf, nil
+}
@@ -0,0 +1,345 @@
+package go_mhda
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compScheme is extension component with name of wallet derivation scheme
+const compScheme = `ws`
+
+const (
+	placeholderAccount = `account`
+	placeholderIndex   = `index`
+)
+
+// schemeLevel is level of scheme layout, constant or replaced by account or address index
+type schemeLevel struct {
+	level       PathLevel
+	placeholder string
+}
+
+// Scheme is named layout of wallet vendor, which defines levels of account and address index,
+// e.g. "m/44'/60'/{account}'/0/0" for Ledger Live
+type Scheme struct {
+	name           string
+	derivationType DerivationType
+	layout         string
+	levels         []schemeLevel
+}
+
+var (
+	schemesMu    sync.RWMutex
+	schemesIndex = map[string]*Scheme{}
+)
+
+// Built-in schemes of wallet vendors. Layouts are defined for Ethereum and Solana,
+// "ethereum" is standard BIP44 layout of coin 60, Exodus uses single address per asset
+var (
+	SchemeEthereum   = MustNewScheme(`ethereum`, BIP44, `m/44'/60'/{account}'/0/{index}`)
+	SchemeExodus     = MustNewScheme(`exodus`, BIP44, `m/44'/60'/0'/0/0`)
+	SchemeLedgerLive = MustNewScheme(`ledger-live`, BIP44, `m/44'/60'/{account}'/0/0`)
+	SchemeMetaMask   = MustNewScheme(`metamask`, BIP44, `m/44'/60'/0'/0/{index}`)
+	SchemeMEWLegacy  = MustNewScheme(`mew-legacy`, CUSTOM, `m/44'/60'/0'/{index}`)
+	SchemeTrust      = MustNewScheme(`trust`, BIP44, `m/44'/60'/0'/0/{index}`)
+	SchemePhantom    = MustNewScheme(`phantom`, SLIP10, `m/44'/501'/{account}'/0'`)
+	SchemeSolflare   = MustNewScheme(`solflare`, SLIP10, `m/44'/501'/{account}'`)
+)
+
+func init() {
+	for _, scheme := range []*Scheme{
+		SchemeEthereum,
+		SchemeExodus,
+		SchemeLedgerLive,
+		SchemeMetaMask,
+		SchemeMEWLegacy,
+		SchemeTrust,
+		SchemePhantom,
+		SchemeSolflare,
+	} {
+		schemesIndex[scheme.name] = scheme
+	}
+}
+
+// NewScheme creates scheme from layout, where "{account}" and "{index}" placeholders
+// define levels of account and address index, e.g. "m/44'/60'/0'/{index}".
+// Layout is validated by grammar of derivation type
+func NewScheme(name string, derivationType DerivationType, layout string) (*Scheme, error) {
+	name = strings.ToLower(name)
+
+	if !isComponentKey(name) {
+		return nil, newParseError(compScheme, 0, name, ErrBadScheme)
+	}
+
+	if len(layout) < 2 || layout[0] != 109 || layout[1] != 47 { // 109 [m], 47 [/]
+		return nil, newParseError(compScheme, 0, layout, ErrBadScheme)
+	}
+
+	s := &Scheme{
+		name:           name,
+		derivationType: derivationType,
+		layout:         layout,
+	}
+
+	offset := 2
+
+	for _, item := range strings.Split(layout[2:], `/`) {
+		var level schemeLevel
+
+		body := item
+
+		if len(body) > 0 && isHardenedMarker(body[len(body)-1]) {
+			level.level.IsHardened = true
+			body = body[:len(body)-1]
+		}
+
+		switch body {
+		case `{` + placeholderAccount + `}`:
+			level.placeholder = placeholderAccount
+		case `{` + placeholderIndex + `}`:
+			level.placeholder = placeholderIndex
+		default:
+			value, err := strconv.ParseUint(body, 10, 32)
+			if err != nil || value > maxLevelIndex {
+				return nil, newParseError(compScheme, offset, layout, ErrBadScheme)
+			}
+			level.level.Index = uint32(value)
+		}
+
+		if level.placeholder != `` && s.placeholderLevel(level.placeholder) >= 0 {
+			return nil, newParseError(compScheme, offset, layout, ErrBadScheme)
+		}
+
+		s.levels = append(s.levels, level)
+
+		offset += len(item) + 1
+	}
+
+	if _, err := NewDerivationPathFromLevels(derivationType, s.pathLevels(0, 0)...); err != nil {
+		return nil, newParseError(compScheme, 0, layout, ErrBadScheme)
+	}
+
+	return s, nil
+}
+
+// MustNewScheme is like NewScheme, but panics on error
+func MustNewScheme(name string, derivationType DerivationType, layout string) *Scheme {
+	s, err := NewScheme(name, derivationType, layout)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// RegisterScheme adds scheme to registry, used by LookupScheme, DetectSchemes and "ws" component
+func RegisterScheme(scheme *Scheme) error {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+
+	if _, ok := schemesIndex[scheme.name]; ok {
+		return newParseError(compScheme, 0, scheme.name, ErrSchemeRegistered)
+	}
+
+	schemesIndex[scheme.name] = scheme
+
+	return nil
+}
+
+// UnregisterScheme removes scheme from registry
+func UnregisterScheme(name string) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+
+	delete(schemesIndex, strings.ToLower(name))
+}
+
+// LookupScheme returns registered scheme by name
+func LookupScheme(name string) (*Scheme, bool) {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+
+	scheme, ok := schemesIndex[strings.ToLower(name)]
+
+	return scheme, ok
+}
+
+// Schemes returns sorted names of registered schemes
+func Schemes() []string {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+
+	names := make([]string, 0, len(schemesIndex))
+
+	for name := range schemesIndex {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// DetectSchemes returns registered schemes, sorted by name, which layouts match path.
+// Several schemes can share layout, e.g. "metamask" and "trust"
+func DetectSchemes(path *DerivationPath) []*Scheme {
+	var result []*Scheme
+
+	for _, name := range Schemes() {
+		scheme, ok := LookupScheme(name)
+		if ok && scheme.Match(path) {
+			result = append(result, scheme)
+		}
+	}
+
+	return result
+}
+
+func (s *Scheme) Name() string {
+	return s.name
+}
+
+func (s *Scheme) DerivationType() DerivationType {
+	return s.derivationType
+}
+
+func (s *Scheme) placeholderLevel(placeholder string) int {
+	for i := range s.levels {
+		if s.levels[i].placeholder == placeholder {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Scheme) pathLevels(account AccountIndex, index uint32) []PathLevel {
+	levels := make([]PathLevel, len(s.levels))
+
+	for i := range s.levels {
+		levels[i] = s.levels[i].level
+
+		switch s.levels[i].placeholder {
+		case placeholderAccount:
+			levels[i].Index = uint32(account)
+		case placeholderIndex:
+			levels[i].Index = index
+		}
+	}
+
+	return levels
+}
+
+// PathFor returns path of account and address index. It returns nil, when value is out of range,
+// or when account or index is not zero, but layout has no level for it
+func (s *Scheme) PathFor(account AccountIndex, index uint32) *DerivationPath {
+	if (account != 0 && s.placeholderLevel(placeholderAccount) < 0) ||
+		(index != 0 && s.placeholderLevel(placeholderIndex) < 0) {
+		return nil
+	}
+
+	path, err := NewDerivationPathFromLevels(s.derivationType, s.pathLevels(account, index)...)
+
+	if err != nil {
+		return nil
+	}
+
+	return path
+}
+
+// Match returns true, when path follows scheme layout
+func (s *Scheme) Match(path *DerivationPath) bool {
+	_, _, ok := s.Indexes(path)
+	return ok
+}
+
+// Indexes returns account and address index of path, which follows scheme layout
+func (s *Scheme) Indexes(path *DerivationPath) (AccountIndex, uint32, bool) {
+	var (
+		account AccountIndex
+		index   uint32
+	)
+
+	if path.Type() != s.derivationType || len(path.levels) != len(s.levels) {
+		return 0, 0, false
+	}
+
+	for i := range s.levels {
+		level := path.levels[i]
+
+		if level.IsHardened != s.levels[i].level.IsHardened {
+			return 0, 0, false
+		}
+
+		switch s.levels[i].placeholder {
+		case placeholderAccount:
+			account = AccountIndex(level.Index)
+		case placeholderIndex:
+			index = level.Index
+		default:
+			if level.Index != s.levels[i].level.Index {
+				return 0, 0, false
+			}
+		}
+	}
+
+	return account, index, true
+}
+
+func (s *Scheme) String() string {
+	return s.layout
+}
+
+func validateSchemeName(value string) error {
+	if _, ok := LookupScheme(value); !ok {
+		return ErrUnknownScheme
+	}
+	return nil
+}
+
+// Scheme returns scheme, defined by "ws" component
+func (a *Address) Scheme() (*Scheme, bool) {
+	name, ok := a.Extension(compScheme)
+
+	if !ok {
+		return nil, false
+	}
+
+	return LookupScheme(name)
+}
+
+// SetScheme sets "ws" component, path of address must follow scheme layout,
+// empty name removes component
+func (a *Address) SetScheme(name string) error {
+	name = strings.ToLower(name)
+
+	if name != `` {
+		scheme, ok := LookupScheme(name)
+		if !ok {
+			return newParseError(compScheme, 0, name, ErrUnknownScheme)
+		}
+
+		if !scheme.Match(a.path) {
+			return newParseError(compScheme, 0, name, ErrSchemeMismatch)
+		}
+	}
+
+	return a.SetExtension(compScheme, name)
+}
+
+// validateScheme checks, that path follows scheme of "ws" component
+func (a *Address) validateScheme() error {
+	name, ok := a.Extension(compScheme)
+
+	if !ok {
+		return nil
+	}
+
+	if scheme, ok := LookupScheme(name); ok && !scheme.Match(a.path) {
+		return newParseError(compScheme, 0, name, ErrSchemeMismatch)
+	}
+
+	return nil
+}
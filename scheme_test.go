@@ -0,0 +1,167 @@
+package go_mhda
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSchemePathFor(t *testing.T) {
+	for _, tt := range []struct {
+		scheme   *Scheme
+		account  AccountIndex
+		index    uint32
+		expected string
+	}{
+		{SchemeLedgerLive, 3, 0, `m/44'/60'/3'/0/0`},
+		{SchemeMetaMask, 0, 7, `m/44'/60'/0'/0/7`},
+		{SchemeMEWLegacy, 0, 2, `m/44'/60'/0'/2`},
+		{SchemeEthereum, 1, 4, `m/44'/60'/1'/0/4`},
+		{SchemeExodus, 0, 0, `m/44'/60'/0'/0/0`},
+		{SchemePhantom, 5, 0, `m/44'/501'/5'/0'`},
+		{SchemeSolflare, 2, 0, `m/44'/501'/2'`},
+	} {
+		path := tt.scheme.PathFor(tt.account, tt.index)
+		if path == nil || path.String() != tt.expected {
+			t.Errorf("%s: unexpected path %s", tt.scheme.Name(), path)
+			continue
+		}
+
+		account, index, ok := tt.scheme.Indexes(path)
+		if !ok || account != tt.account || index != tt.index {
+			t.Errorf("%s: unexpected indexes %d %d", tt.scheme.Name(), account, index)
+		}
+	}
+
+	if SchemeLedgerLive.PathFor(0, 1) != nil || SchemeMetaMask.PathFor(1, 0) != nil || SchemeEthereum.PathFor(1<<31, 0) != nil {
+		t.Error("unexpected path out of scheme layout")
+	}
+}
+
+func TestDetectSchemes(t *testing.T) {
+	for src, expected := range map[string][]string{
+		`m/44'/60'/0'/0/0`: {`ethereum`, `exodus`, `ledger-live`, `metamask`, `trust`},
+		`m/44'/60'/2'/0/0`: {`ethereum`, `ledger-live`},
+		`m/44'/60'/0'/0/9`: {`ethereum`, `metamask`, `trust`},
+		`m/44'/60'/1'/1/0`: nil,
+	} {
+		path, err := ParseDerivationPath(BIP44, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+
+		for _, scheme := range DetectSchemes(path) {
+			names = append(names, scheme.Name())
+		}
+
+		if len(names) != len(expected) {
+			t.Errorf("%s: unexpected schemes %v", src, names)
+			continue
+		}
+
+		for i := range names {
+			if names[i] != expected[i] {
+				t.Errorf("%s: unexpected schemes %v", src, names)
+			}
+		}
+	}
+
+	path, _ := ParseDerivationPath(CUSTOM, `m/44'/60'/0'/3`)
+
+	if schemes := DetectSchemes(path); len(schemes) != 1 || schemes[0] != SchemeMEWLegacy {
+		t.Errorf("unexpected schemes %v", schemes)
+	}
+}
+
+func TestRegisterScheme(t *testing.T) {
+	scheme, err := NewScheme(`coinbase-eth`, BIP44, `m/44'/60'/{account}'/0/{index}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = RegisterScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	defer UnregisterScheme(`coinbase-eth`)
+
+	if err = RegisterScheme(scheme); !errors.Is(err, ErrSchemeRegistered) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if found, ok := LookupScheme(`Coinbase-ETH`); !ok || found != scheme {
+		t.Error("scheme is not registered")
+	}
+
+	for _, layout := range []string{
+		`44'/60'/0'/0/0`,
+		`m/44'/60'/{account}'/{account}/0`,
+		`m/44'/60'/{wallet}'/0/0`,
+		`m/44'/60'/0'/2/{index}`,
+		`m/44'/60'/0'/0/{index}/0`,
+	} {
+		if _, err = NewScheme(`broken`, BIP44, layout); !errors.Is(err, ErrBadScheme) {
+			t.Errorf("%s: unexpected error %v", layout, err)
+		}
+	}
+}
+
+func TestAddressScheme(t *testing.T) {
+	address, err := ParseURN(`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/4h/0/0:ws:ledger-live`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheme, ok := address.(*Address).Scheme()
+	if !ok || scheme != SchemeLedgerLive {
+		t.Fatal("unexpected scheme")
+	}
+
+	if address.String() != `urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/4'/0/0:ws:ledger-live` {
+		t.Errorf("unexpected address %s", address)
+	}
+
+	mixed, err := ParseURN(`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/3:ws:MetaMask`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lower, err := ParseURN(`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/0h/0/3:ws:metamask`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mixed.NSS() != lower.NSS() || mixed.Hash() != lower.Hash() {
+		t.Errorf("scheme name must be case-insensitive, got %s", mixed.NSS())
+	}
+
+	if err = mixed.(*Address).SetExtension(`ws`, `Trust`); err != nil {
+		t.Fatal(err)
+	}
+
+	if name, _ := mixed.(*Address).Extension(`ws`); name != `trust` {
+		t.Errorf("unexpected scheme name %q", name)
+	}
+
+	if err = address.(*Address).SetScheme(`metamask`); !errors.Is(err, ErrSchemeMismatch) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if err = address.(*Address).SetScheme(`unknown`); !errors.Is(err, ErrUnknownScheme) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	for src, target := range map[string]error{
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/4h/0/0:ws:metamask`: ErrSchemeMismatch,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/4h/0/0:ws:unknown`:  ErrUnknownScheme,
+	} {
+		if _, err = ParseURN(src); !errors.Is(err, target) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+
+	if _, err = ParseURN(`urn:mhda:nt:evm:ct:60:ci:0x1:ws:unknown`); !errors.Is(err, ErrBadExtension) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
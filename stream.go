@@ -0,0 +1,215 @@
+package go_mhda
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// StreamOptions configures ParseStream
+type StreamOptions struct {
+	// ParseOptions is used for every line
+	ParseOptions ParseOptions
+	// Workers is number of parsing goroutines, lines are parsed sequentially for values less than 2.
+	// Results are always sent in source order
+	Workers int
+}
+
+// StreamResult is result of single line parsing
+type StreamResult struct {
+	// Line is line number, starting from 1
+	Line    int
+	Address MHDA
+	Err     error
+}
+
+type streamJob struct {
+	line   int
+	src    string
+	result chan StreamResult
+}
+
+// ParseStream reads newline-delimited URNs from r and sends parsed addresses to returned channel.
+// Empty lines are skipped, wrong lines are reported with Err and parsing continues.
+// Read error is sent as last result. Channel is closed, when r is exhausted or ctx is done
+func ParseStream(ctx context.Context, r io.Reader, opts StreamOptions) <-chan StreamResult {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+
+	results := make(chan StreamResult, opts.Workers+1)
+
+	if opts.Workers < 2 {
+		go parseStreamSequential(ctx, r, &opts, results)
+	} else {
+		go parseStreamParallel(ctx, r, &opts, results)
+	}
+
+	return results
+}
+
+func parseStreamLine(line int, src string, opts *ParseOptions) StreamResult {
+	address, err := ParseURNWithOptions(src, *opts)
+	return StreamResult{
+		Line:    line,
+		Address: address,
+		Err:     err,
+	}
+}
+
+// maxStreamLine is maximal length of stream line, longer lines are reported with ErrLineTooLong
+const maxStreamLine = 64 * 1024
+
+// scanStream calls fn for each non-empty line until fn returns false,
+// lines longer than maxStreamLine are passed with error
+func scanStream(r io.Reader, fn func(line int, src string, err error) bool) (int, error) {
+	var (
+		reader  = bufio.NewReader(r)
+		buf     []byte
+		line    = 0
+		tooLong = false
+	)
+
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err == io.EOF {
+			return line + 1, nil
+		}
+		if err != nil {
+			return line + 1, err
+		}
+
+		if !tooLong {
+			if len(buf)+len(chunk) > maxStreamLine {
+				tooLong = true
+			} else {
+				buf = append(buf, chunk...)
+			}
+		}
+
+		if isPrefix {
+			continue
+		}
+
+		line++
+
+		ok := true
+
+		if tooLong {
+			ok = fn(line, ``, newParseError(``, maxStreamLine, ``, ErrLineTooLong))
+		} else if src := strings.TrimSpace(string(buf)); src != `` {
+			ok = fn(line, src, nil)
+		}
+
+		if !ok {
+			return line, nil
+		}
+
+		buf = buf[:0]
+		tooLong = false
+	}
+}
+
+func sendResult(ctx context.Context, results chan<- StreamResult, result StreamResult) bool {
+	select {
+	case results <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func parseStreamSequential(ctx context.Context, r io.Reader, opts *StreamOptions, results chan<- StreamResult) {
+	defer close(results)
+
+	line, err := scanStream(r, func(line int, src string, err error) bool {
+		if err != nil {
+			return sendResult(ctx, results, StreamResult{Line: line, Err: err})
+		}
+		return sendResult(ctx, results, parseStreamLine(line, src, &opts.ParseOptions))
+	})
+
+	if err != nil {
+		sendResult(ctx, results, StreamResult{Line: line, Err: err})
+	}
+}
+
+func parseStreamParallel(ctx context.Context, r io.Reader, opts *StreamOptions, results chan<- StreamResult) {
+	jobs := make(chan *streamJob, opts.Workers)
+	queue := make(chan *streamJob, opts.Workers)
+	done := make(chan struct{})
+
+	for i := 0; i < opts.Workers; i++ {
+		go func() {
+			for job := range jobs {
+				job.result <- parseStreamLine(job.line, job.src, &opts.ParseOptions)
+			}
+		}()
+	}
+
+	// keeps source order, waiting results of jobs one by one
+	go func() {
+		defer close(done)
+		defer close(results)
+
+		for job := range queue {
+			var result StreamResult
+			select {
+			case result = <-job.result:
+			case <-ctx.Done():
+				return
+			}
+			if !sendResult(ctx, results, result) {
+				return
+			}
+		}
+	}()
+
+	line, err := scanStream(r, func(line int, src string, err error) bool {
+		job := &streamJob{
+			line:   line,
+			src:    src,
+			result: make(chan StreamResult, 1),
+		}
+
+		// line error is queued with result, without worker
+		if err != nil {
+			job.result <- StreamResult{Line: line, Err: err}
+		}
+
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+			return false
+		}
+
+		if err != nil {
+			return true
+		}
+
+		// job is queued, so it must be always processed to release worker
+		jobs <- job
+
+		return true
+	})
+
+	close(jobs)
+
+	if err != nil {
+		job := &streamJob{
+			line:   line,
+			result: make(chan StreamResult, 1),
+		}
+		job.result <- StreamResult{Line: line, Err: err}
+
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+		}
+	}
+
+	close(queue)
+
+	<-done
+}
@@ -0,0 +1,88 @@
+package go_mhda
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseStream(t *testing.T) {
+	var sb strings.Builder
+
+	for i := 0; i < 100; i++ {
+		if i%10 == 5 {
+			sb.WriteString("urn:mhda:nt:evm:ct:bad:ci:1\n")
+		} else if i%10 == 7 {
+			sb.WriteString("\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("urn:mhda:nt:evm:dt:bip44:dp:m/44'/60'/0'/0/%d:ct:60:ci:1\n", i))
+		}
+	}
+
+	for _, workers := range []int{-3, 0, 1, 4} {
+		var line, failed int
+
+		for result := range ParseStream(context.Background(), strings.NewReader(sb.String()), StreamOptions{Workers: workers}) {
+			if result.Line <= line {
+				t.Fatalf("unordered result %d after %d", result.Line, line)
+			}
+			line = result.Line
+
+			if result.Err != nil {
+				if !errors.Is(result.Err, ErrBadCoinType) || result.Line%10 != 6 {
+					t.Fatalf("unexpected error on line %d: %s", result.Line, result.Err)
+				}
+				failed++
+				continue
+			}
+
+			if result.Address.DerivationPath().AddressIndex().Index != uint32(result.Line-1) {
+				t.Fatalf("unexpected address on line %d: %s", result.Line, result.Address)
+			}
+		}
+
+		if line != 100 || failed != 10 {
+			t.Fatalf("unexpected results count, last line %d, failed %d", line, failed)
+		}
+	}
+}
+
+func TestParseStreamLongLine(t *testing.T) {
+	src := "urn:mhda:nt:evm:ct:60:ci:1:as:" + strings.Repeat("a", maxStreamLine) + "\n" +
+		"urn:mhda:nt:evm:ct:60:ci:0x38\n"
+
+	for _, workers := range []int{1, 4} {
+		var results []StreamResult
+
+		for result := range ParseStream(context.Background(), strings.NewReader(src), StreamOptions{Workers: workers}) {
+			results = append(results, result)
+		}
+
+		if len(results) != 2 {
+			t.Fatalf("unexpected results count %d", len(results))
+		}
+
+		if results[0].Line != 1 || !errors.Is(results[0].Err, ErrLineTooLong) {
+			t.Fatalf("unexpected result of long line: %v", results[0].Err)
+		}
+
+		if results[1].Line != 2 || results[1].Err != nil || results[1].Address.Chain().ChainId() != `0x38` {
+			t.Fatalf("unexpected result after long line: %v", results[1].Err)
+		}
+	}
+}
+
+func TestParseStreamCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	src := strings.Repeat("urn:mhda:nt:evm:ct:60:ci:1\n", 1000)
+	results := ParseStream(ctx, strings.NewReader(src), StreamOptions{Workers: 4})
+
+	<-results
+	cancel()
+
+	for range results {
+	}
+}
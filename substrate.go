@@ -0,0 +1,149 @@
+package go_mhda
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+const (
+	// junctionHard is separator of hard junction, soft junction is separated by single "/"
+	junctionHard = `//`
+	// junctionPassword is separator of password, which is the last part of derivation path
+	junctionPassword = `///`
+)
+
+// Junction is single junction of Substrate derivation path
+// https://docs.substrate.io/reference/command-line-tools/subkey/
+type Junction struct {
+	Value  string
+	IsHard bool
+}
+
+// IsNumeric returns true, when junction is encoded as unsigned 64-bit integer
+func (j Junction) IsNumeric() bool {
+	_, err := strconv.ParseUint(j.Value, 10, 64)
+	return err == nil
+}
+
+// Encode returns SCALE encoding of junction: little-endian u64 for numeric values,
+// otherwise compact length prefixed string. Chain code of junction is not derived here
+func (j Junction) Encode() []byte {
+	if value, err := strconv.ParseUint(j.Value, 10, 64); err == nil {
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, value)
+		return buf
+	}
+
+	var (
+		length = uint32(len(j.Value))
+		buf    []byte
+	)
+
+	switch {
+	case length < 1<<6:
+		buf = []byte{byte(length << 2)}
+	case length < 1<<14:
+		buf = make([]byte, 2)
+		binary.LittleEndian.PutUint16(buf, uint16(length<<2|1))
+	default:
+		buf = make([]byte, 4)
+		binary.LittleEndian.PutUint32(buf, length<<2|2)
+	}
+
+	return append(buf, j.Value...)
+}
+
+func (j Junction) String() string {
+	if j.IsHard {
+		return junctionHard + j.Value
+	}
+	return `/` + j.Value
+}
+
+// parseJunctions parses Substrate path "//hard/soft///password" with at least one junction,
+// path is not changed on error
+func (dp *DerivationPath) parseJunctions(path string) error {
+	var (
+		junctions []Junction
+		password  string
+		pos       = 0
+	)
+
+	if idx := strings.Index(path, junctionPassword); idx >= 0 {
+		password = path[idx+len(junctionPassword):]
+		if password == `` {
+			return newParseError(compDerivationPath, idx, path, ErrBadDerivationPath)
+		}
+		path = path[:idx]
+	}
+
+	if path == `` {
+		return newParseError(compDerivationPath, 0, path, ErrBadDerivationPath)
+	}
+
+	for pos < len(path) {
+		if path[pos] != 47 { // 47 [/]
+			return newParseError(compDerivationPath, pos, path, ErrBadDerivationPath)
+		}
+
+		junction := Junction{IsHard: strings.HasPrefix(path[pos:], junctionHard)}
+
+		if junction.IsHard {
+			pos += len(junctionHard)
+		} else {
+			pos++
+		}
+
+		end := strings.IndexByte(path[pos:], '/')
+		if end < 0 {
+			end = len(path) - pos
+		}
+
+		if end == 0 {
+			return newParseError(compDerivationPath, pos, path, ErrBadDerivationPath)
+		}
+
+		junction.Value = path[pos : pos+end]
+		junctions = append(junctions, junction)
+
+		pos += end
+	}
+
+	dp.levels = dp.levels[:0]
+	dp.junctions = junctions
+	dp.password = password
+
+	return nil
+}
+
+// Junctions returns copy of Substrate path junctions
+func (dp *DerivationPath) Junctions() []Junction {
+	if dp == nil {
+		return nil
+	}
+
+	result := make([]Junction, len(dp.junctions))
+	copy(result, dp.junctions)
+
+	return result
+}
+
+// Password returns password of Substrate path, it is not written by String()
+// and is not a part of address hash
+func (dp *DerivationPath) Password() string {
+	if dp == nil {
+		return ``
+	}
+	return dp.password
+}
+
+func (dp *DerivationPath) junctionsString() string {
+	var sb strings.Builder
+
+	for i := range dp.junctions {
+		sb.WriteString(dp.junctions[i].String())
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package go_mhda
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSubstrateJunctions(t *testing.T) {
+	address, err := ParseURN(`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://Polkadot//0/1///s3cret`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := address.DerivationPath()
+
+	expected := []Junction{{`Polkadot`, true}, {`0`, true}, {`1`, false}}
+
+	if !reflect.DeepEqual(path.Junctions(), expected) || path.Password() != `s3cret` || path.Depth() != 3 {
+		t.Errorf("unexpected junctions %v", path.Junctions())
+	}
+
+	if address.String() != `urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://Polkadot//0/1` {
+		t.Errorf("unexpected address %s", address)
+	}
+
+	if address.LongNSS() != `sv:2:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://Polkadot//0/1:aa:sr25519:af:ss58` {
+		t.Errorf("unexpected long nss %s", address.LongNSS())
+	}
+
+	withoutPassword, err := ParseURN(`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://Polkadot//0/1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address.Hash() != withoutPassword.Hash() {
+		t.Error("password changes hash")
+	}
+
+	if _, err = ParseURNWithOptions(`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://Hard`, ParseOptions{CaseSensitiveHardened: true}); err != nil {
+		t.Error(err)
+	}
+
+	for _, src := range []string{
+		`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp:polkadot`,
+		`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://polkadot///`,
+		`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp:///password`,
+		`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://a//`,
+		`urn:mhda:nt:dot:ct:354:ci:polkadot:dt:substrate:dp://polkadot/0:aa:ed25519`,
+	} {
+		if _, err = ParseURN(src); !errors.Is(err, ErrBadDerivationPath) {
+			t.Errorf("%s: unexpected error %v", src, err)
+		}
+	}
+}
+
+func TestJunctionEncode(t *testing.T) {
+	for _, tt := range []struct {
+		junction Junction
+		expected []byte
+	}{
+		{Junction{Value: `0`, IsHard: true}, []byte{0, 0, 0, 0, 0, 0, 0, 0}},
+		{Junction{Value: `258`}, []byte{2, 1, 0, 0, 0, 0, 0, 0}},
+		{Junction{Value: `Alice`, IsHard: true}, []byte{20, 'A', 'l', 'i', 'c', 'e'}},
+		{Junction{Value: string(bytes.Repeat([]byte{'a'}, 64))}, append([]byte{1, 1}, bytes.Repeat([]byte{'a'}, 64)...)},
+	} {
+		if encoded := tt.junction.Encode(); !bytes.Equal(encoded, tt.expected) {
+			t.Errorf("%s: unexpected encoding %x", tt.junction, encoded)
+		}
+	}
+
+	if !(Junction{Value: `42`}).IsNumeric() || (Junction{Value: `polkadot`}).IsNumeric() {
+		t.Error("unexpected numeric junction")
+	}
+}
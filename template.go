@@ -0,0 +1,249 @@
+package go_mhda
+
+import (
+	"strconv"
+	"strings"
+)
+
+// templatePart is literal text or named placeholder of Template
+type templatePart struct {
+	literal     string
+	placeholder string
+	component   string
+	offset      int
+	// level is depth of derivation path level, which contains placeholder
+	level int
+}
+
+// Template is URN or NSS with named placeholders for numeric values, e.g.
+// "urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/{account}h/0/{index}"
+type Template struct {
+	src            string
+	isURN          bool
+	derivationType DerivationType
+	parts          []templatePart
+	placeholders   []string
+}
+
+// ParseTemplate parses template and validates it with the same grammar as ParseURN
+// or ParseNSS, using minimal values for all placeholders: zero, or minimal value
+// of derivation path level, e.g. script type of BIP48
+func ParseTemplate(src string) (*Template, error) {
+	t, err := parseTemplate(src)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]uint32, len(t.placeholders))
+
+	for _, name := range t.placeholders {
+		values[name] = 0
+	}
+
+	grammar := derivationIndex[t.derivationType]
+
+	for i := range t.parts {
+		if t.parts[i].placeholder == `` || t.parts[i].component != compDerivationPath {
+			continue
+		}
+
+		if rule, ok := grammar.rule(t.parts[i].level); ok && rule.min > values[t.parts[i].placeholder] {
+			values[t.parts[i].placeholder] = rule.min
+		}
+	}
+
+	if _, err = t.Expand(values); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func parseTemplate(src string) (*Template, error) {
+	t := &Template{src: src}
+
+	nss := src
+	offset := 0
+
+	if len(src) >= prefixOffset && strings.EqualFold(src[:prefixOffset], prefixMHDA) {
+		t.isURN = true
+		t.parts = append(t.parts, templatePart{literal: src[:prefixOffset]})
+		nss = src[prefixOffset:]
+		offset = prefixOffset
+	}
+
+	var rqf string
+
+	if idx := strings.IndexAny(nss, `?#`); idx >= 0 {
+		rqf = nss[idx:]
+		nss = nss[:idx]
+	}
+
+	tokens := strings.Split(nss, `:`)
+
+	for i := range tokens {
+		if i > 0 {
+			t.parts = append(t.parts, templatePart{literal: `:`})
+		}
+
+		if i%2 == 0 {
+			if strings.IndexByte(tokens[i], '{') >= 0 {
+				return nil, newParseError(tokens[i], offset, tokens[i], ErrBadTemplate)
+			}
+			t.parts = append(t.parts, templatePart{literal: tokens[i]})
+		} else {
+			component := strings.ToLower(tokens[i-1])
+
+			if component == compDerivationType {
+				t.derivationType = DerivationType(strings.ToLower(tokens[i]))
+			}
+
+			err := t.parseValue(component, tokens[i], offset)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		offset += len(tokens[i]) + 1
+	}
+
+	if rqf != `` {
+		err := t.parseValue(``, rqf, offset-1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t *Template) parseValue(component, value string, offset int) error {
+	src := value
+
+	for {
+		start := strings.IndexByte(value, '{')
+		if start < 0 {
+			if strings.IndexByte(value, '}') >= 0 {
+				return newParseError(component, offset, value, ErrBadTemplate)
+			}
+			if value != `` {
+				t.parts = append(t.parts, templatePart{literal: value})
+			}
+			return nil
+		}
+
+		end := strings.IndexByte(value[start:], '}')
+		if end < 0 {
+			return newParseError(component, offset+start, value, ErrBadTemplate)
+		}
+		end += start
+
+		name := value[start+1 : end]
+		if !isPlaceholderName(name) || strings.IndexByte(value[:start], '}') >= 0 {
+			return newParseError(component, offset+start, value, ErrBadTemplate)
+		}
+
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{literal: value[:start]})
+		}
+
+		t.parts = append(t.parts, templatePart{
+			placeholder: name,
+			component:   component,
+			offset:      offset + start,
+			level:       strings.Count(src[:len(src)-len(value)+start], `/`) - 1,
+		})
+
+		if !t.hasPlaceholder(name) {
+			t.placeholders = append(t.placeholders, name)
+		}
+
+		offset += end + 1
+		value = value[end+1:]
+	}
+}
+
+// isPlaceholderName checks name syntax: [a-z_][a-z0-9_]*
+func isPlaceholderName(name string) bool {
+	if name == `` || !((name[0] >= 97 && name[0] <= 122) || name[0] == 95) {
+		return false
+	}
+
+	for i := 1; i < len(name); i++ {
+		if !((name[i] >= 97 && name[i] <= 122) || (name[i] >= 48 && name[i] <= 57) || name[i] == 95) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *Template) hasPlaceholder(name string) bool {
+	for i := range t.placeholders {
+		if t.placeholders[i] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Placeholders returns placeholders names in order of first appearance
+func (t *Template) Placeholders() []string {
+	result := make([]string, len(t.placeholders))
+	copy(result, t.placeholders)
+	return result
+}
+
+// Expand substitutes placeholders and parses result. Placeholders of derivation path
+// must be less than 2^31 for both hardened and non-hardened levels, hardened levels
+// are marked in template, e.g. "{account}h"
+func (t *Template) Expand(values map[string]uint32) (*Address, error) {
+	for name := range values {
+		if !t.hasPlaceholder(name) {
+			return nil, newParseError(``, 0, name, ErrUnknownPlaceholder)
+		}
+	}
+
+	var sb strings.Builder
+
+	sb.Grow(len(t.src))
+
+	for i := range t.parts {
+		if t.parts[i].placeholder == `` {
+			sb.WriteString(t.parts[i].literal)
+			continue
+		}
+
+		value, ok := values[t.parts[i].placeholder]
+		if !ok {
+			return nil, newParseError(t.parts[i].component, t.parts[i].offset, t.parts[i].placeholder, ErrMissingPlaceholder)
+		}
+
+		if t.parts[i].component == compDerivationPath && value > maxLevelIndex {
+			return nil, newParseError(t.parts[i].component, t.parts[i].offset, t.parts[i].placeholder, ErrPlaceholderRange)
+		}
+
+		sb.WriteString(strconv.FormatUint(uint64(value), 10))
+	}
+
+	address := &Address{}
+
+	var err error
+
+	if t.isURN {
+		err = parseURNInto(sb.String(), address, &LenientParseOptions)
+	} else {
+		err = parseNSSInto(sb.String(), address, &LenientParseOptions)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+func (t *Template) String() string {
+	return t.src
+}
@@ -0,0 +1,87 @@
+package go_mhda
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	tpl, err := ParseTemplate(`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/{account}h/0/{index}?=label={account}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(tpl.Placeholders(), []string{`account`, `index`}) {
+		t.Fatalf("unexpected placeholders %v", tpl.Placeholders())
+	}
+
+	addr, err := tpl.Expand(map[string]uint32{`account`: 2, `index`: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44'/60'/2'/0/15?=label=2`
+	if addr.String() != expected {
+		t.Fatal("mismatch result", addr.String(), expected)
+	}
+
+	cases := []struct {
+		values map[string]uint32
+		err    error
+	}{
+		{map[string]uint32{`account`: 1}, ErrMissingPlaceholder},
+		{map[string]uint32{`account`: 1, `index`: 1, `change`: 1}, ErrUnknownPlaceholder},
+		{map[string]uint32{`account`: 1 << 31, `index`: 1}, ErrPlaceholderRange},
+		{map[string]uint32{`account`: 1, `index`: 1 << 31}, ErrPlaceholderRange},
+	}
+
+	for _, c := range cases {
+		if _, err = tpl.Expand(c.values); !errors.Is(err, c.err) {
+			t.Fatalf("expected %v for %v, got %v", c.err, c.values, err)
+		}
+	}
+
+	for _, src := range []string{
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/{account/0/0`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/{Account}h/0/0`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:{key}:1`,
+		`urn:mhda:nt:evm:ct:60:ci:0x1:dt:bip44:dp:m/44h/60h/{account}/0/0`,
+	} {
+		if _, err = ParseTemplate(src); err == nil {
+			t.Fatalf("expected error for %s", src)
+		}
+	}
+
+	nss, err := ParseTemplate(`nt:btc:ct:0:ci:bitcoin:dt:bip84:dp:m/84h/0h/0h/{change}/{index}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err = nss.Expand(map[string]uint32{`change`: 1, `index`: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr.NSS() != `nt:btc:ct:0:ci:bitcoin:dt:bip84:dp:m/84'/0'/0'/1/7` {
+		t.Fatal("mismatch result", addr.NSS())
+	}
+
+	multisig, err := ParseTemplate(`urn:mhda:nt:btc:ct:0:ci:bitcoin:dt:bip48:dp:m/48h/0h/0h/{script}h/0/{index}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, err = multisig.Expand(map[string]uint32{`script`: 2, `index`: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr.NSS() != `nt:btc:ct:0:ci:bitcoin:dt:bip48:dp:m/48'/0'/0'/2'/0/3` {
+		t.Fatal("mismatch result", addr.NSS())
+	}
+
+	if _, err = multisig.Expand(map[string]uint32{`script`: 0, `index`: 3}); !errors.Is(err, ErrBadDerivationPath) {
+		t.Fatalf("expected %v, got %v", ErrBadDerivationPath, err)
+	}
+}
@@ -0,0 +1,113 @@
+package go_mhda
+
+import "strconv"
+
+// SpecVersion is version of MHDA grammar, defined by optional "sv" component
+type SpecVersion uint8
+
+const (
+	// SpecVersion1 is initial grammar: address format is defined by "ad" key,
+	// components order is nt, dt, dp, ct, ci, aa, ad, ap, as
+	SpecVersion1 = SpecVersion(1)
+	// SpecVersion2 is RFC 8141 compatible grammar with percent-encoded values,
+	// components order is nt, ct, ci, dt, dp, aa, af, ap, as
+	SpecVersion2 = SpecVersion(2)
+
+	CurrentSpecVersion = SpecVersion2
+)
+
+// ranksVersion1 is components order of SpecVersion1 by positions of current grammar
+var ranksVersion1 = [componentsCount]int{
+	indexSpecVersion:      0,
+	indexNetworkType:      1,
+	indexDerivationType:   2,
+	indexDerivationPath:   3,
+	indexCoinType:         4,
+	indexChainId:          5,
+	indexAddressAlgorithm: 6,
+	indexAddressFormat:    7,
+	indexAddressPrefix:    8,
+	indexAddressSuffix:    9,
+}
+
+func (v SpecVersion) String() string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func parseSpecVersion(src string) (SpecVersion, error) {
+	version, err := strconv.ParseUint(src, 10, 8)
+
+	if err != nil || version == 0 {
+		return 0, newParseError(compSpecVersion, 0, src, ErrBadSpecVersion)
+	}
+
+	return SpecVersion(version), nil
+}
+
+// componentRank returns position of component in canonical order of grammar version
+func componentRank(version SpecVersion, index int) int {
+	if version == SpecVersion1 {
+		return ranksVersion1[index]
+	}
+	return index
+}
+
+// upgradeLegacy detects grammar version, when "sv" is not defined, and converts
+// components of older versions to current grammar
+func (c *nssComponents) upgradeLegacy(opts *ParseOptions) error {
+	isExplicit := c.values[indexSpecVersion].isSet
+
+	if !isExplicit {
+		c.version = CurrentSpecVersion
+		if c.legacy.addressFormat.isSet || c.legacy.isOrder {
+			c.version = SpecVersion1
+		}
+	}
+
+	if !c.legacy.addressFormat.isSet {
+		return nil
+	}
+
+	legacy := c.legacy.addressFormat
+
+	if c.version > SpecVersion1 {
+		// "ad" is not defined since SpecVersion2
+		if opts.RejectUnknown {
+			return newParseError(compLegacyAddressFormat, legacy.offset, legacy.value, ErrUnknownComponent)
+		}
+		return nil
+	}
+
+	if c.values[indexAddressFormat].isSet {
+		if opts.RejectDuplicates {
+			return newParseError(compLegacyAddressFormat, legacy.offset, legacy.value, ErrDuplicateComponent)
+		}
+		return nil
+	}
+
+	c.values[indexAddressFormat] = legacy
+
+	return nil
+}
+
+// DetectVersion returns grammar version of URN. Version is defined by "sv" component,
+// or detected by legacy spellings, otherwise CurrentSpecVersion is returned
+func DetectVersion(src string) (SpecVersion, error) {
+	address := &Address{}
+
+	err := parseURNInto(src, address, &LenientParseOptions)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return address.SpecVersion(), nil
+}
+
+// SpecVersion returns grammar version of parsed URN
+func (a *Address) SpecVersion() SpecVersion {
+	if a.specVersion == 0 {
+		return CurrentSpecVersion
+	}
+	return a.specVersion
+}